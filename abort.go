@@ -0,0 +1,25 @@
+package workflowsgo
+
+// AbortStep is the NextStep of the events built by AbortEvent. A run
+// reaching such an event stops as failed: the error is passed to the
+// onError callback, or to the output callback when there is none, and the
+// compensations of the run are executed, like for any other error that
+// stops a run.
+const AbortStep = "__abort__"
+
+// AbortReasonKey is the key of the Data of the events built by AbortEvent
+// that holds the reason of the abort.
+const AbortReasonKey = "abortReason"
+
+// AbortEvent builds the event a step returns to abort the workflow with an
+// error, instead of routing to 'end' with an output. The run stops with a
+// *StepError wrapping ErrAborted, naming the step that aborted it and
+// reporting reason.
+func AbortEvent(reason string) *BaseEvent {
+	return NewBaseEvent(AbortStep, map[string]string{AbortReasonKey: reason})
+}
+
+// abortError returns the error that stops a run aborted by ev.
+func abortError(ev *BaseEvent) *StepError {
+	return stepError(ev.SourceStep, ErrAborted, "step %s aborted the workflow: %s", ev.SourceStep, ev.Data[AbortReasonKey])
+}
@@ -0,0 +1,31 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAbortEvent(t *testing.T) {
+	steps := map[string]StepFunc{
+		"check": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			if ev.Data["user"] == "" {
+				return AbortEvent("missing user")
+			}
+			return NewBaseEvent("end", map[string]string{"output": "ok"})
+		},
+	}
+	wf := NewBaseWorkflow("check", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	var failedStep string
+	var failure error
+	outputs := []any{}
+	wf.Run(context.Background(), NewBaseEvent("check", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) { outputs = append(outputs, out) }, func(stepName string, err error) {
+		failedStep, failure = stepName, err
+	})
+	if failedStep != "check" || !errors.Is(failure, ErrAborted) || failure.Error() != "step check aborted the workflow: missing user" || len(outputs) != 0 {
+		t.Errorf("Testing AbortEvent: want onError to fire for 'check' with the reason and no output, got %q, %v and %v", failedStep, failure, outputs)
+	}
+	if _, err := wf.RunSync(context.Background(), NewBaseEvent("check", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{})); !errors.Is(err, ErrAborted) {
+		t.Errorf("Testing AbortEvent with RunSync: want ErrAborted, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// AsyncKey is the key of BaseEvent.Data that flags the events routed onward
+// by the steps built with Async, while their work goes on in the
+// background.
+const AsyncKey = "async"
+
+// Async returns a step that starts step in its own goroutine, with a copy
+// of the incoming event, and immediately routes the incoming event, flagged
+// under AsyncKey, to nextStep, so that the workflow does not block. The
+// event returned by step is delivered on the returned future, which a later
+// step awaits with Join.
+//
+// The future holds a single result, so the returned step is meant to be
+// executed once per run; if it is executed again before the previous result
+// is received, the new result is delivered after it. A panic of step is
+// recovered and delivered as an event carrying the error under ErrorKey.
+func Async(step StepFunc, nextStep string) (StepFunc, <-chan *BaseEvent) {
+	future := make(chan *BaseEvent, 1)
+	asyncStep := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		input := NewBaseEvent(ev.NextStep, maps.Clone(ev.Data))
+		go func() {
+			var result *BaseEvent
+			defer func() {
+				if r := recover(); r != nil {
+					result = NewBaseEvent("", map[string]string{ErrorKey: fmt.Sprintf("async step panicked: %v", r)})
+				}
+				future <- result
+			}()
+			result = step(ctx, input, wfCtx)
+		}()
+		data := maps.Clone(ev.Data)
+		if data == nil {
+			data = map[string]string{}
+		}
+		data[AsyncKey] = "true"
+		return NewBaseEvent(nextStep, data)
+	}
+	return asyncStep, future
+}
+
+// Join returns a step that waits for the results of the given futures,
+// built with Async, and routes to nextStep an event merging the Data of the
+// incoming event with the Data of the results, in the order of futures, as
+// MergeEvents does; the AsyncKey flag is removed. If the context.Context is
+// cancelled while waiting, the event carries the cancellation error under
+// ErrorKey instead, with the results received so far.
+func Join(futures []<-chan *BaseEvent, nextStep string) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		events := []*BaseEvent{ev}
+		var err error
+	wait:
+		for _, future := range futures {
+			select {
+			case result := <-future:
+				events = append(events, result)
+			case <-ctx.Done():
+				err = ctx.Err()
+				break wait
+			}
+		}
+		merged := MergeEvents(events...)
+		delete(merged.Data, AsyncKey)
+		if err != nil {
+			merged.Data[ErrorKey] = fmt.Sprintf("join stopped before completion: %v", err)
+		}
+		merged.NextStep = nextStep
+		return merged
+	}
+}
@@ -0,0 +1,50 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAsyncJoin(t *testing.T) {
+	release := make(chan struct{})
+	summarize := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		<-release
+		return NewBaseEvent("", map[string]string{"summary": "short " + ev.Data["document"]})
+	}
+	translate := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("", map[string]string{"translation": "translated " + ev.Data["document"]})
+	}
+	summarizeStep, summary := Async(summarize, "translate")
+	translateStep, translation := Async(translate, "join")
+	steps := map[string]StepFunc{
+		"translate": translateStep,
+		"join":      Join([]<-chan *BaseEvent{summary, translation}, "answer"),
+		"answer": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			if _, ok := ev.Data[AsyncKey]; ok {
+				return NewBaseEvent("end", map[string]string{"output": "still flagged as async"})
+			}
+			return NewBaseEvent("end", map[string]string{"output": ev.Data["summary"] + ", " + ev.Data["translation"]})
+		},
+	}
+	steps["summarize"] = func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		event := summarizeStep(ctx, ev, wfCtx)
+		if event.Data[AsyncKey] != "true" {
+			t.Error("Testing Async: expected the event to be flagged as async")
+		}
+		close(release)
+		return event
+	}
+	wf := NewBaseWorkflow("summarize", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("summarize", map[string]string{"document": "doc"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "short doc, translated doc" {
+		t.Errorf("Testing Async and Join: want 'short doc, translated doc' and no error, got %v and %v", out, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	event := Join([]<-chan *BaseEvent{make(chan *BaseEvent)}, "answer")(ctx, NewBaseEvent("join", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if _, ok := event.Data[ErrorKey]; !ok || event.NextStep != "answer" {
+		t.Errorf("Testing Join with a cancelled context: want an error event routing to answer, got %v", event)
+	}
+}
@@ -0,0 +1,39 @@
+package workflowsgo
+
+import (
+	"context"
+	"sync"
+)
+
+// RunBatch runs the workflow for each of the input events, with at most
+// concurrency runs at a time, and returns their outputs in the order of
+// events. Each run gets its own clone of wfCtx (or an empty context, if
+// wfCtx is nil), so that runs do not see each other's values. The output
+// of a run that fails is its error, as passed to the output callback of
+// Run. A concurrency lower than 1 runs the events one at a time.
+func (wf *BaseWorkflow) RunBatch(ctx context.Context, events []*BaseEvent, wfCtx *BaseContext, concurrency int) []any {
+	if wfCtx == nil {
+		wfCtx = NewBaseContext(map[string]any{}, map[string]any{})
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	outputs := make([]any, len(events))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(events); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				wf.RunWith(ctx, events[i], wfCtx.Clone(), WithOnOutput(func(out any) { outputs[i] = out }))
+			}
+		}()
+	}
+	for i := range events {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return outputs
+}
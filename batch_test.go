@@ -0,0 +1,47 @@
+package workflowsgo
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkflowRunBatch(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	double := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if _, ok := wfCtx.GetValue("seen"); ok {
+			return NewBaseEvent("end", map[string]string{"output": "shared context"})
+		}
+		wfCtx.StoreValue("seen", true)
+		time.Sleep(time.Millisecond)
+		i, _ := strconv.Atoi(ev.Data["i"])
+		return NewBaseEvent("end", map[string]string{"output": strconv.Itoa(2 * i)})
+	}
+	wf := NewBaseWorkflow("double", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"double": double})
+	events := make([]*BaseEvent, 100)
+	for i := range events {
+		events[i] = NewBaseEvent("double", map[string]string{"i": strconv.Itoa(i)})
+	}
+	outputs := wf.RunBatch(context.Background(), events, NewBaseContext(map[string]any{}, map[string]any{}), 4)
+	if len(outputs) != 100 {
+		t.Fatalf("Testing BaseWorkflow.RunBatch: want 100 outputs, got %d", len(outputs))
+	}
+	for i, out := range outputs {
+		if out != strconv.Itoa(2*i) {
+			t.Errorf("Testing BaseWorkflow.RunBatch: want output %d to be %d, got %v", i, 2*i, out)
+		}
+	}
+	if m := maxRunning.Load(); m > 4 {
+		t.Errorf("Testing BaseWorkflow.RunBatch: want at most 4 concurrent runs, got %d", m)
+	}
+}
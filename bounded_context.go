@@ -0,0 +1,53 @@
+package workflowsgo
+
+import "sync"
+
+// BoundedContext is an implementation of GenericContext whose Store holds
+// at most a fixed number of keys, to bound the memory used by long-running
+// workflows: when StoreValue adds a key to a full Store, the least recently
+// used key is evicted. Both StoreValue and GetValue mark a key as recently
+// used. It is safe for concurrent use.
+type BoundedContext struct {
+	store *lruCache[any]
+
+	mu    sync.RWMutex
+	state map[string]any
+}
+
+// NewBoundedContext is a constructor that returns an empty BoundedContext
+// holding at most maxEntries keys, or an unbounded number of them if
+// maxEntries is 0 or less.
+func NewBoundedContext(maxEntries int) *BoundedContext {
+	return &BoundedContext{store: newLRUCache[any](maxEntries), state: map[string]any{}}
+}
+
+// StoreValue stores a key-value pair, evicting the least recently used key
+// if the Store is full.
+func (bc *BoundedContext) StoreValue(key string, val any) {
+	bc.store.add(key, val)
+}
+
+// GetValue fetches the value associated with a key, marking it as recently
+// used.
+func (bc *BoundedContext) GetValue(key string) (any, bool) {
+	return bc.store.get(key)
+}
+
+// Len returns the number of keys held by the Store.
+func (bc *BoundedContext) Len() int {
+	return bc.store.len()
+}
+
+// GetState fetches the State, which is not bounded.
+func (bc *BoundedContext) GetState() map[string]any {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.state
+}
+
+// SetState assigns a value to the State.
+func (bc *BoundedContext) SetState(state map[string]any) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.state = state
+}
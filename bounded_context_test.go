@@ -0,0 +1,23 @@
+package workflowsgo
+
+import "testing"
+
+func TestBoundedContext(t *testing.T) {
+	var ctx GenericContext = NewBoundedContext(3)
+	ctx.StoreValue("a", 1)
+	ctx.StoreValue("b", 2)
+	ctx.StoreValue("c", 3)
+	ctx.GetValue("a")
+	ctx.StoreValue("d", 4)
+	if _, ok := ctx.GetValue("b"); ok {
+		t.Error("Testing BoundedContext: want the least recently used key 'b' evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3, "d": 4} {
+		if val, ok := ctx.GetValue(key); !ok || val != want {
+			t.Errorf("Testing BoundedContext: want %d under %q, got %v, %v", want, key, val, ok)
+		}
+	}
+	if size := ctx.(*BoundedContext).Len(); size != 3 {
+		t.Errorf("Testing BoundedContext.Len: want 3, got %d", size)
+	}
+}
@@ -0,0 +1,96 @@
+package workflowsgo
+
+import "sync"
+
+// CallbackBuffer queues the callbacks of the runs it is given to with
+// WithCallbackBuffer, invoking them one at a time, in order, on a worker
+// goroutine, so that slow callbacks do not hold up the steps of the runs.
+// Flush waits for the queued callbacks to be invoked, e.g. before reading
+// the results they aggregate. A CallbackBuffer is safe for concurrent use
+// and can be shared by several runs.
+type CallbackBuffer struct {
+	queue   chan func()
+	done    chan struct{}
+	mu      sync.Mutex
+	idle    *sync.Cond
+	pending int
+}
+
+// NewCallbackBuffer returns a CallbackBuffer queueing up to size callbacks
+// (at least one): when the queue is full, the run waits for the worker to
+// catch up. Close stops the worker.
+func NewCallbackBuffer(size int) *CallbackBuffer {
+	if size < 1 {
+		size = 1
+	}
+	buf := &CallbackBuffer{queue: make(chan func(), size), done: make(chan struct{})}
+	buf.idle = sync.NewCond(&buf.mu)
+	go buf.work()
+	return buf
+}
+
+// work invokes the queued callbacks until the buffer is closed.
+func (buf *CallbackBuffer) work() {
+	defer close(buf.done)
+	for cb := range buf.queue {
+		cb()
+		buf.mu.Lock()
+		buf.pending--
+		if buf.pending == 0 {
+			buf.idle.Broadcast()
+		}
+		buf.mu.Unlock()
+	}
+}
+
+// enqueue queues cb for the worker.
+func (buf *CallbackBuffer) enqueue(cb func()) {
+	buf.mu.Lock()
+	buf.pending++
+	buf.mu.Unlock()
+	buf.queue <- cb
+}
+
+// Flush blocks until the queue is empty and every queued callback has been
+// invoked, including those queued while Flush waits.
+func (buf *CallbackBuffer) Flush() {
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	for buf.pending > 0 {
+		buf.idle.Wait()
+	}
+}
+
+// Close invokes the queued callbacks and stops the worker. The buffer must
+// not be used by any run after Close.
+func (buf *CallbackBuffer) Close() {
+	close(buf.queue)
+	<-buf.done
+}
+
+// WithCallbackBuffer makes the run queue its OnStart, OnEnd and OnOutput
+// callbacks on buf instead of invoking them from the run itself; OnError
+// is still invoked synchronously. The callbacks receive copies of the
+// events, so that they can be read while the run goes on. Since the run
+// does not wait for them, call buf.Flush before reading what they record.
+func WithCallbackBuffer(buf *CallbackBuffer) RunOption {
+	return func(opts *RunOptions) {
+		opts.buffer = buf
+	}
+}
+
+// buffered replaces the callbacks of opts with ones queueing them on buf.
+func (opts *RunOptions) buffered(buf *CallbackBuffer) {
+	onStart, onEnd, onOutput := opts.OnStart, opts.OnEnd, opts.OnOutput
+	opts.OnStart = func(ev *BaseEvent) {
+		ev = ev.Clone()
+		buf.enqueue(func() { onStart(ev) })
+	}
+	opts.OnEnd = func(ev *BaseEvent) {
+		ev = ev.Clone()
+		buf.enqueue(func() { onEnd(ev) })
+	}
+	opts.OnOutput = func(output any) {
+		buf.enqueue(func() { onOutput(output) })
+	}
+}
@@ -0,0 +1,40 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCallbackBuffer(t *testing.T) {
+	steps := map[string]StepFunc{
+		"plan": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("answer", ev.Data)
+		},
+		"answer": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "42"})
+		},
+	}
+	wf := NewBaseWorkflow("plan", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	buf := NewCallbackBuffer(2)
+	defer buf.Close()
+	var recorded []string
+	record := func(entry string) {
+		time.Sleep(5 * time.Millisecond)
+		recorded = append(recorded, entry)
+	}
+	for i := 0; i < 2; i++ {
+		wf.RunWith(context.Background(), NewBaseEvent("plan", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}),
+			WithCallbackBuffer(buf),
+			WithOnStart(func(ev *BaseEvent) { record("start " + ev.NextStep) }),
+			WithOnEnd(func(ev *BaseEvent) { record("end " + ev.SourceStep) }),
+			WithOnOutput(func(out any) { record("output " + out.(string)) }),
+		)
+	}
+	buf.Flush()
+	run := []string{"start answer", "end answer", "start end", "output 42"}
+	if want := append(slices.Clone(run), run...); !slices.Equal(recorded, want) {
+		t.Errorf("Testing CallbackBuffer.Flush: want %v, got %v", want, recorded)
+	}
+}
@@ -0,0 +1,54 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// WithChangeLog is a ContextOption that appends a JSON line to w for every
+// mutation of the context, e.g. for auditing or event sourcing: each line
+// records the time of the mutation, according to the Clock of the context,
+// the operation ("store" for StoreValue and StoreValueWithTTL, "setState"
+// for SetState), the key, for stores, and the old and new values. Values
+// that cannot be encoded as JSON are recorded with their fmt
+// representation.
+//
+// Writes that leave a value unchanged, as reported by reflect.DeepEqual,
+// are not logged, and neither are the mutations made by accessing Store
+// and State directly or by Rollback. Clones of the context do not log
+// their mutations. Errors writing to w are ignored.
+func WithChangeLog(w io.Writer) ContextOption {
+	return func(ctx *BaseContext) {
+		ctx.changeLog = &changeLog{w: w}
+	}
+}
+
+// changeLog writes the lines of WithChangeLog, one at a time.
+type changeLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// changeEntry is a line written by changeLog.
+type changeEntry struct {
+	Time time.Time `json:"time"`
+	Op   string    `json:"op"`
+	Key  string    `json:"key,omitempty"`
+	Old  any       `json:"old"`
+	New  any       `json:"new"`
+}
+
+func (cl *changeLog) append(clock Clock, op, key string, old, val any) {
+	entry := changeEntry{Time: clock.Now(), Op: op, Key: key, Old: old, New: val}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		entry.Old, entry.New = fmt.Sprint(old), fmt.Sprint(val)
+		line, _ = json.Marshal(entry)
+	}
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.w.Write(append(line, '\n'))
+}
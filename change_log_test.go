@@ -0,0 +1,30 @@
+package workflowsgo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithChangeLog(t *testing.T) {
+	var buf bytes.Buffer
+	clock := NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{}, WithChangeLog(&buf), WithClock(clock))
+	wfCtx.StoreValue("status", "pending")
+	wfCtx.StoreValue("status", "pending")
+	wfCtx.SetState(map[string]any{"phase": 1})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`{"time":"2024-01-02T03:04:05Z","op":"store","key":"status","old":null,"new":"pending"}`,
+		`{"time":"2024-01-02T03:04:05Z","op":"setState","old":{},"new":{"phase":1}}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Testing WithChangeLog: want %d lines, got %d: %q", len(want), len(lines), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Testing WithChangeLog: want line %d to be %s, got %s", i, want[i], lines[i])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// checkpoint is the JSON representation of a paused workflow run.
+type checkpoint struct {
+	Event *BaseEvent     `json:"event"`
+	Store map[string]any `json:"store"`
+	State map[string]any `json:"state"`
+}
+
+// Checkpoint serializes to JSON the last emitted event, together with the
+// Store and State of the context, so that the run can be restored with
+// Resume and continued with RunFrom.
+//
+// The values held by the context must be JSON-serializable: if they are
+// not (e.g. channels or functions), Checkpoint returns an error. Since
+// values are restored from JSON, structs are resumed as map[string]any
+// and numbers as float64.
+func (wf *BaseWorkflow) Checkpoint(ev *BaseEvent, ctx *BaseContext) ([]byte, error) {
+	defer ctx.rlock()()
+	b, err := json.Marshal(checkpoint{Event: ev, Store: ctx.Store, State: ctx.State})
+	if err != nil {
+		return nil, fmt.Errorf("could not checkpoint the workflow: %w", err)
+	}
+	return b, nil
+}
+
+// Resume restores the event and the context serialized with Checkpoint.
+func (wf *BaseWorkflow) Resume(data []byte) (*BaseEvent, *BaseContext, error) {
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, nil, fmt.Errorf("could not resume the workflow: %w", err)
+	}
+	if cp.Event == nil {
+		return nil, nil, fmt.Errorf("could not resume the workflow: the checkpoint holds no event")
+	}
+	if cp.Store == nil {
+		cp.Store = map[string]any{}
+	}
+	if cp.State == nil {
+		cp.State = map[string]any{}
+	}
+	return cp.Event, NewBaseContext(cp.Store, cp.State), nil
+}
@@ -0,0 +1,59 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	visited := []string{}
+	steps := map[string]StepFunc{
+		"first": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			visited = append(visited, "first")
+			wfCtx.StoreValue("draft", "hello")
+			return NewBaseEvent("second", map[string]string{"stage": "drafted"})
+		},
+		"second": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			visited = append(visited, "second")
+			draft, _ := wfCtx.GetValue("draft")
+			return NewBaseEvent("end", map[string]string{"output": draft.(string) + " world"})
+		},
+	}
+	wf := NewBaseWorkflow("first", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{"attempt": 1})
+	event := wf.TakeStep(context.Background(), "first", NewBaseEvent("first", map[string]string{}), wfCtx)
+	data, err := wf.Checkpoint(event, wfCtx)
+	if err != nil {
+		t.Fatalf("Testing BaseWorkflow.Checkpoint: unexpected error %v", err)
+	}
+
+	resumedEvent, resumedCtx, err := wf.Resume(data)
+	if err != nil {
+		t.Fatalf("Testing BaseWorkflow.Resume: unexpected error %v", err)
+	}
+	if resumedEvent.NextStep != "second" {
+		t.Errorf("Testing BaseWorkflow.Resume: Expected 'second', gotten %s", resumedEvent.NextStep)
+	}
+	if val, _ := resumedCtx.GetValue("draft"); val != "hello" {
+		t.Errorf("Testing BaseWorkflow.Resume: Expected 'hello', gotten %v", val)
+	}
+	if val := resumedCtx.GetState()["attempt"]; val != float64(1) {
+		t.Errorf("Testing BaseWorkflow.Resume: Expected 1, gotten %v", val)
+	}
+
+	outputs := []any{}
+	wf.RunFrom(context.Background(), resumedEvent, resumedCtx, func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, nil)
+	if !slices.Equal(visited, []string{"first", "second"}) || !slices.Equal(outputs, []any{"hello world"}) {
+		t.Errorf("Testing BaseWorkflow.RunFrom: want %v and %v, got %v and %v", []string{"first", "second"}, []any{"hello world"}, visited, outputs)
+	}
+
+	if _, err := wf.Checkpoint(event, NewBaseContext(map[string]any{"ch": make(chan int)}, map[string]any{})); err == nil {
+		t.Error("Testing BaseWorkflow.Checkpoint with a non-serializable value: expected an error")
+	}
+	if _, _, err := wf.Resume([]byte("not json")); err == nil {
+		t.Error("Testing BaseWorkflow.Resume with malformed data: expected an error")
+	}
+}
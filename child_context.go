@@ -0,0 +1,31 @@
+package workflowsgo
+
+// ChildContext returns a scratch context for a step or a sub-flow: GetValue
+// reads through to ctx for the keys the child does not hold, while
+// StoreValue and the State of the child are local to it, so that ctx is
+// left untouched until Merge is called. The child shares the Clock of ctx.
+func (ctx *BaseContext) ChildContext() *BaseContext {
+	child := NewBaseContext(map[string]any{}, map[string]any{}, WithClock(ctx.clock))
+	child.parent = ctx
+	return child
+}
+
+// Merge promotes the given keys of the Store of a context created with
+// ChildContext to its parent, or all of them when no key is given. Keys
+// the child does not hold, or that have expired, are skipped. Merge has no
+// effect on contexts without a parent.
+func (ctx *BaseContext) Merge(keys ...string) {
+	if ctx.parent == nil {
+		return
+	}
+	if len(keys) == 0 {
+		unlock := ctx.rlock()
+		keys = sortedKeys(ctx.Store)
+		unlock()
+	}
+	for _, key := range keys {
+		if val, ok := ctx.getLocalValue(key); ok {
+			ctx.parent.StoreValue(key, val)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package workflowsgo
+
+import "testing"
+
+func TestChildContext(t *testing.T) {
+	parent := NewBaseContext(map[string]any{"user": "ada"}, map[string]any{})
+	child := parent.ChildContext()
+	if val, ok := child.GetValue("user"); !ok || val != "ada" {
+		t.Errorf("Testing ChildContext: want the child to read 'ada' from the parent, got %v, %v", val, ok)
+	}
+	child.StoreValue("draft", "v1")
+	child.StoreValue("scratch", "tmp")
+	child.StoreValue("user", "grace")
+	if _, ok := parent.GetValue("draft"); ok {
+		t.Error("Testing ChildContext: the parent should not see values written to the child before Merge")
+	}
+	if val, _ := parent.GetValue("user"); val != "ada" {
+		t.Errorf("Testing ChildContext: want the parent value to be left untouched, got %v", val)
+	}
+	if val, _ := child.GetValue("user"); val != "grace" {
+		t.Errorf("Testing ChildContext: want the child to read its own value, got %v", val)
+	}
+	child.Merge("draft", "missing")
+	if val, ok := parent.GetValue("draft"); !ok || val != "v1" {
+		t.Errorf("Testing BaseContext.Merge: want 'draft' promoted to the parent, got %v, %v", val, ok)
+	}
+	if _, ok := parent.GetValue("scratch"); ok {
+		t.Error("Testing BaseContext.Merge: 'scratch' was not selected and should not be promoted")
+	}
+	child.Merge()
+	if val, _ := parent.GetValue("scratch"); val != "tmp" {
+		t.Errorf("Testing BaseContext.Merge without keys: want every key promoted, got %v", val)
+	}
+}
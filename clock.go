@@ -0,0 +1,98 @@
+package workflowsgo
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by the time-dependent features of the
+// package, such as value expiration in BaseContext and the WithTimeout and
+// WithRetry step wrappers. Injecting a FakeClock makes them deterministic
+// under test.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel that receives the current time once the duration has elapsed.
+	After(time.Duration) <-chan time.Time
+}
+
+// RealClock is the implementation of Clock backed by the time package.
+type RealClock struct{}
+
+// Now returns time.Now().
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// fakeTimer is a channel returned by FakeClock.After, waiting for its deadline.
+type fakeTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// FakeClock is an implementation of Clock whose time only moves when
+// Advance is called. It is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+// NewFakeClock is a constructor that returns a FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now,
+	}
+}
+
+// Now returns the current time of the FakeClock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the time of the FakeClock once it
+// has been advanced by at least d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the time of the FakeClock forward by d, firing the
+// channels returned by After whose deadline has been reached.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			timer.ch <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+// pendingTimers returns the number of channels returned by After that have
+// not fired yet.
+func (c *FakeClock) pendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
@@ -0,0 +1,51 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	fired := clock.After(time.Minute)
+	clock.Advance(30 * time.Second)
+	select {
+	case <-fired:
+		t.Error("Testing FakeClock.After: the channel fired before the deadline")
+	default:
+	}
+	clock.Advance(30 * time.Second)
+	select {
+	case now := <-fired:
+		if !now.Equal(start.Add(time.Minute)) {
+			t.Errorf("Testing FakeClock.After: want %v, got %v", start.Add(time.Minute), now)
+		}
+	default:
+		t.Error("Testing FakeClock.After: the channel did not fire after the deadline")
+	}
+	if !clock.Now().Equal(start.Add(time.Minute)) {
+		t.Errorf("Testing FakeClock.Now: want %v, got %v", start.Add(time.Minute), clock.Now())
+	}
+}
+
+func TestWithTimeoutFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{}, WithClock(clock))
+	blocked := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		<-ctx.Done()
+		return NewBaseEvent("end", map[string]string{"output": "too late"})
+	}
+	result := make(chan *BaseEvent)
+	go func() {
+		result <- WithTimeout(blocked, time.Hour, "fallback")(context.Background(), NewBaseEvent("blocked", map[string]string{}), wfCtx)
+	}()
+	for clock.pendingTimers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Hour)
+	if event := <-result; event.NextStep != "fallback" {
+		t.Errorf("Testing WithTimeout with a FakeClock: Expected 'fallback', gotten %s", event.NextStep)
+	}
+}
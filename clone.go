@@ -0,0 +1,54 @@
+package workflowsgo
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Clone returns a copy of the BaseEvent with its own Data map, so that
+// mutating the copy does not affect the original. Cloning a nil BaseEvent
+// returns nil.
+func (ev *BaseEvent) Clone() *BaseEvent {
+	if ev == nil {
+		return nil
+	}
+	cp := *ev
+	cp.Data = maps.Clone(ev.Data)
+	cp.NextSteps = slices.Clone(ev.NextSteps)
+	return &cp
+}
+
+// Clone returns a copy of the BaseContext with its own Store and State
+// maps, history and lock, sharing the same Clock and ContextRand. The copy is shallow with
+// respect to the values held by the maps: values stored by reference (e.g.
+// pointers, slices or maps) are shared between the original and the copy.
+func (ctx *BaseContext) Clone() *BaseContext {
+	defer ctx.rlock()()
+	cp := &BaseContext{
+		Store:       maps.Clone(ctx.Store),
+		State:       maps.Clone(ctx.State),
+		mu:          &sync.RWMutex{},
+		expirations: maps.Clone(ctx.expirations),
+		clock:       ctx.clock,
+		history:     make([]*BaseEvent, len(ctx.history)),
+		timeline:    slices.Clone(ctx.timeline),
+		parent:      ctx.parent,
+		rng:         ctx.rng,
+		keyTypes:    maps.Clone(ctx.keyTypes),
+		strictTypes: ctx.strictTypes,
+	}
+	if cp.Store == nil {
+		cp.Store = map[string]any{}
+	}
+	if cp.State == nil {
+		cp.State = map[string]any{}
+	}
+	if trace, ok := cp.State[TraceKey].([]string); ok {
+		cp.State[TraceKey] = slices.Clone(trace)
+	}
+	for i, ev := range ctx.history {
+		cp.history[i] = ev.Clone()
+	}
+	return cp
+}
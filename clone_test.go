@@ -0,0 +1,41 @@
+package workflowsgo
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestEventClone(t *testing.T) {
+	template := NewBaseEvent("summarize", map[string]string{"lang": "en"})
+	clone := template.Clone()
+	clone.NextStep = "translate"
+	clone.Data["lang"] = "it"
+	if template.NextStep != "summarize" || template.Data["lang"] != "en" {
+		t.Errorf("Testing BaseEvent.Clone: the original was modified: %v", template)
+	}
+	if (*BaseEvent)(nil).Clone() != nil {
+		t.Error("Testing BaseEvent.Clone on nil: expected nil")
+	}
+}
+
+func TestContextClone(t *testing.T) {
+	user := &User{"John Doe", "john.doe@example.com", 30}
+	template := NewBaseContext(map[string]any{"user": user, "credit": 200}, map[string]any{"iterations": 1})
+	clone := template.Clone()
+	if !maps.Equal(clone.Store, template.Store) || !maps.Equal(clone.State, template.State) {
+		t.Errorf("Testing BaseContext.Clone: want %v and %v, got %v and %v", template.Store, template.State, clone.Store, clone.State)
+	}
+	clone.StoreValue("credit", 100)
+	clone.SetState(map[string]any{"iterations": 2})
+	clone.GetState()["extra"] = true
+	if credit, _ := template.GetValue("credit"); credit != 200 {
+		t.Errorf("Testing BaseContext.Clone: the original Store was modified: %v", template.Store)
+	}
+	if len(template.GetState()) != 1 || template.GetState()["iterations"] != 1 {
+		t.Errorf("Testing BaseContext.Clone: the original State was modified: %v", template.State)
+	}
+	clonedUser, _ := clone.GetValue("user")
+	if clonedUser.(*User) != user {
+		t.Error("Testing BaseContext.Clone: expected values to be copied shallowly")
+	}
+}
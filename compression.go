@@ -0,0 +1,61 @@
+package workflowsgo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/AstraBert/workflows-go/internal/gzipjson"
+)
+
+// compressValues returns the values of m ready to be persisted in the file
+// of a FileContext, with the sorted keys of the ones that were compressed:
+// the values whose JSON encoding is longer than threshold bytes are
+// replaced by the base64 encoding of their gzipjson encoding, while the
+// others are left as they are. A threshold of 0 or less disables
+// compression.
+func compressValues(m map[string]any, threshold int) (map[string]any, []string, error) {
+	if threshold <= 0 {
+		return m, nil, nil
+	}
+	out := make(map[string]any, len(m))
+	var compressed []string
+	for key, val := range m {
+		b, err := gzipjson.Encode(val, threshold)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not encode the value of %s: %w", key, err)
+		}
+		if !gzipjson.Compressed(b) {
+			out[key] = json.RawMessage(b)
+			continue
+		}
+		out[key] = base64.StdEncoding.EncodeToString(b)
+		compressed = append(compressed, key)
+	}
+	sort.Strings(compressed)
+	return out, compressed, nil
+}
+
+// decompressValues replaces, in place, the values of m under the
+// compressed keys, as returned by compressValues, with the values they
+// hold. The other values are left untouched, whatever they look like, so
+// that files written without compression are read as they are.
+func decompressValues(m map[string]any, compressed []string) error {
+	for _, key := range compressed {
+		encoded, ok := m[key].(string)
+		if !ok {
+			return fmt.Errorf("the compressed value of %s is not a string", key)
+		}
+		b, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("could not decode the value of %s: %w", key, err)
+		}
+		var decoded any
+		if err := gzipjson.Decode(b, &decoded); err != nil {
+			return fmt.Errorf("could not decompress the value of %s: %w", key, err)
+		}
+		m[key] = decoded
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompressValues(t *testing.T) {
+	document := strings.Repeat("a long document ", 1<<10)
+	values := map[string]any{"document": document, "title": "short", "pages": float64(3)}
+	persisted, compressed, err := compressValues(values, 64)
+	if err != nil {
+		t.Fatalf("Testing compressValues: unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(compressed, []string{"document"}) {
+		t.Errorf("Testing compressValues: want only the document compressed, got %v", compressed)
+	}
+	raw, err := json.Marshal(persisted)
+	if err != nil {
+		t.Fatalf("Testing compressValues: unexpected error %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("Testing compressValues: unexpected error %v", err)
+	}
+	if err := decompressValues(decoded, compressed); err != nil {
+		t.Fatalf("Testing decompressValues: unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("Testing decompressValues: want the values to round-trip, got %v", decoded)
+	}
+	if persisted, compressed, _ := compressValues(values, 0); compressed != nil || !reflect.DeepEqual(persisted, values) {
+		t.Errorf("Testing compressValues without a threshold: want the values untouched, got %v and %v", persisted, compressed)
+	}
+	if err := decompressValues(map[string]any{"document": 42.0}, []string{"document"}); err == nil {
+		t.Error("Testing decompressValues with a corrupt value: expected an error")
+	}
+}
+
+func TestFileContextCompressionLegacyAndCollision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "context.json")
+	legacy := `{"store": {"title": "short", "wrapper": {"__gzip__": "not compressed"}}, "state": {"phase": "draft"}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fc, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing NewFileContext with an uncompressed file: unexpected error %v", err)
+	}
+	wrapper := map[string]any{"__gzip__": "not compressed"}
+	if val, _ := fc.GetValue("wrapper"); !reflect.DeepEqual(val, wrapper) {
+		t.Errorf("Testing NewFileContext with an uncompressed file: want %v, got %v", wrapper, val)
+	}
+	if state := fc.GetState(); state["phase"] != "draft" {
+		t.Errorf("Testing NewFileContext with an uncompressed file: want the state to be loaded, got %v", state)
+	}
+	fc.CompressThreshold = 16
+	fc.StoreValue("document", strings.Repeat("a long document ", 64))
+	if err := fc.Err(); err != nil {
+		t.Fatalf("Testing FileContext.CompressThreshold: unexpected error %v", err)
+	}
+	restarted, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing NewFileContext with compressed values: unexpected error %v", err)
+	}
+	if val, _ := restarted.GetValue("wrapper"); !reflect.DeepEqual(val, wrapper) {
+		t.Errorf("Testing NewFileContext with a value looking like a compressed one: want %v, got %v", wrapper, val)
+	}
+	if val, _ := restarted.GetValue("document"); val != strings.Repeat("a long document ", 64) {
+		t.Errorf("Testing NewFileContext with compressed values: the document did not round-trip, got %v", val)
+	}
+}
@@ -0,0 +1,36 @@
+package workflowsgo
+
+// Keys of BaseContext.State under which the runners record the step being
+// executed, see BaseContext.CurrentStep and BaseContext.StepIndex.
+const (
+	CurrentStepKey = "__currentStep__"
+	StepIndexKey   = "__stepIndex__"
+)
+
+// CurrentStep returns the name of the step being executed by the run using
+// this context, e.g. for a step to log its own name, or of the last step
+// executed once the run is over. It returns an empty string if the context
+// was not used by any run.
+func (ctx *BaseContext) CurrentStep() string {
+	defer ctx.rlock()()
+	name, _ := ctx.State[CurrentStepKey].(string)
+	return name
+}
+
+// StepIndex returns the 1-based index, within its run, of the step returned
+// by CurrentStep, or 0 if the context was not used by any run.
+func (ctx *BaseContext) StepIndex() int {
+	defer ctx.rlock()()
+	index, _ := ctx.State[StepIndexKey].(int)
+	return index
+}
+
+// setCurrentStep records the step about to be executed by a run.
+func (ctx *BaseContext) setCurrentStep(stepName string, stepIndex int) {
+	defer ctx.lock()()
+	if ctx.State == nil {
+		ctx.State = map[string]any{}
+	}
+	ctx.State[CurrentStepKey] = stepName
+	ctx.State[StepIndexKey] = stepIndex
+}
@@ -0,0 +1,27 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCurrentStep(t *testing.T) {
+	seen := []string{}
+	introspect := func(next string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			seen = append(seen, fmt.Sprintf("%s#%d", wfCtx.CurrentStep(), wfCtx.StepIndex()))
+			return NewBaseEvent(next, map[string]string{"output": "done"})
+		}
+	}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"retrieve": introspect("generate"), "generate": introspect("end")})
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	if wfCtx.CurrentStep() != "" || wfCtx.StepIndex() != 0 {
+		t.Errorf("Testing BaseContext.CurrentStep before a run: want no step, got %q and %d", wfCtx.CurrentStep(), wfCtx.StepIndex())
+	}
+	wf.RunSync(context.Background(), NewBaseEvent("retrieve", map[string]string{}), wfCtx)
+	if want := []string{"retrieve#1", "generate#2"}; !slices.Equal(seen, want) {
+		t.Errorf("Testing BaseContext.CurrentStep and StepIndex: want %v, got %v", want, seen)
+	}
+}
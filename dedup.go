@@ -0,0 +1,24 @@
+package workflowsgo
+
+import "context"
+
+// Dedup wraps a step so that it processes each IdempotencyKey once, e.g.
+// when a distributed queue delivers the same event twice: for an incoming
+// event whose key was already seen, step is skipped and a copy of the event
+// it returned the first time is returned instead. Events without an
+// IdempotencyKey are always processed.
+//
+// The seen keys are recorded by the returned step, across runs and
+// contexts, keeping at most maxKeys of them and forgetting the least
+// recently seen one when full; a maxKeys of 0 or less keeps them all.
+// Like with Memoize, results carrying a value under ErrorKey are not
+// recorded, so that a failed delivery can be retried.
+func Dedup(step StepFunc, maxKeys int) StepFunc {
+	deduped := Memoize(step, func(ev *BaseEvent) string { return ev.IdempotencyKey }, maxKeys)
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if ev.IdempotencyKey == "" {
+			return step(ctx, ev, wfCtx)
+		}
+		return deduped(ctx, ev, wfCtx)
+	}
+}
@@ -0,0 +1,40 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	charges := 0
+	charge := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		charges++
+		return NewBaseEvent("end", map[string]string{"output": "charged " + ev.Data["amount"]})
+	}
+	wf := NewBaseWorkflow("charge", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"charge": Dedup(charge, 100)})
+	submit := func(key string) any {
+		ev := NewBaseEvent("charge", map[string]string{"amount": "10"})
+		ev.IdempotencyKey = key
+		out, err := wf.RunSync(context.Background(), ev, NewBaseContext(map[string]any{}, map[string]any{}))
+		if err != nil {
+			t.Fatalf("Testing Dedup: unexpected error %v", err)
+		}
+		return out
+	}
+	first, second := submit("payment-1"), submit("payment-1")
+	if charges != 1 || first != "charged 10" || second != "charged 10" {
+		t.Errorf("Testing Dedup with a duplicate key: want a single charge and the same output, got %d charges, %v and %v", charges, first, second)
+	}
+	submit("")
+	submit("")
+	if charges != 3 {
+		t.Errorf("Testing Dedup without a key: want every event processed, got %d charges", charges)
+	}
+	ev := NewBaseEvent("charge", map[string]string{})
+	ev.IdempotencyKey = "payment-2"
+	var decoded BaseEvent
+	if data, err := json.Marshal(ev); err != nil || json.Unmarshal(data, &decoded) != nil || decoded.IdempotencyKey != "payment-2" {
+		t.Errorf("Testing BaseEvent.IdempotencyKey: want it to survive a JSON round trip, got %q", decoded.IdempotencyKey)
+	}
+}
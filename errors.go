@@ -0,0 +1,59 @@
+package workflowsgo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned, wrapped in a *StepError, when validating or running a
+// workflow. Use errors.Is to tell them apart.
+var (
+	// ErrStepNotFound reports a step name that is not registered.
+	ErrStepNotFound = errors.New("step not found")
+	// ErrReservedKeyword reports a step named with the terminal step or
+	// with one of the ReservedSteps.
+	ErrReservedKeyword = errors.New("reserved keyword")
+	// ErrStepLimitExceeded reports a run stopped because it exceeded
+	// MaxSteps.
+	ErrStepLimitExceeded = errors.New("step limit exceeded")
+	// ErrInvalidFirstStep reports a FirstStep that is not a registered
+	// step.
+	ErrInvalidFirstStep = errors.New("invalid first step")
+	// ErrRunnerShutdown reports a run stopped, or refused, because its
+	// Runner is shutting down.
+	ErrRunnerShutdown = errors.New("runner shut down")
+	// ErrMaxDurationExceeded reports a run stopped because it exceeded
+	// MaxDuration.
+	ErrMaxDurationExceeded = errors.New("maximum duration exceeded")
+	// ErrAborted reports a run aborted by a step, see AbortEvent.
+	ErrAborted = errors.New("workflow aborted")
+	// ErrSuspendNotSupported reports a step suspending a run that cannot
+	// be resumed, such as one started with RunWithEventBus.
+	ErrSuspendNotSupported = errors.New("suspension not supported")
+)
+
+// StepError is an error about a specific step. Err is one of the errors
+// above, so that errors.Is(err, ErrStepNotFound) holds for a *StepError
+// reporting a missing step, while errors.As gives access to Step.
+type StepError struct {
+	Step string
+	Err  error
+	msg  string
+}
+
+// stepError builds a *StepError whose message is formatted from format and
+// args.
+func stepError(step string, err error, format string, args ...any) *StepError {
+	return &StepError{Step: step, Err: err, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *StepError) Error() string {
+	if e.msg == "" {
+		return fmt.Sprintf("%v: %s", e.Err, e.Step)
+	}
+	return e.msg
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
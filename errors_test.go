@@ -0,0 +1,38 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStepErrors(t *testing.T) {
+	steps := map[string]StepFunc{"first": mockStep}
+	wf := NewBaseWorkflow("frist", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	_, err := wf.Validate()
+	var stepErr *StepError
+	if !errors.Is(err, ErrInvalidFirstStep) || !errors.As(err, &stepErr) || stepErr.Step != "frist" {
+		t.Errorf("Testing Validate with an unregistered first step: want a *StepError wrapping ErrInvalidFirstStep for 'frist', got %v", err)
+	}
+	wf.FirstStep = "first"
+	wf.Transitions = map[string][]string{"first": {"thrid"}}
+	if _, err := wf.Validate(); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing Validate with a transition to an unregistered step: want ErrStepNotFound, got %v", err)
+	}
+	if err := wf.AddStep("end", mockStep); !errors.Is(err, ErrReservedKeyword) {
+		t.Errorf("Testing AddStep with a reserved keyword: want ErrReservedKeyword, got %v", err)
+	}
+	if _, err := wf.TakeStepE(context.Background(), "unknown", NewBaseEvent("unknown", map[string]string{}), wf.Context); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing TakeStepE with an unregistered step: want ErrStepNotFound, got %v", err)
+	}
+	loop := NewBaseWorkflow("loop", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{
+		"loop": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("loop", map[string]string{})
+		},
+	})
+	loop.MaxSteps = 3
+	_, err = loop.RunSync(context.Background(), NewBaseEvent("loop", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if !errors.Is(err, ErrStepLimitExceeded) || !errors.As(err, &stepErr) || stepErr.Step != "loop" {
+		t.Errorf("Testing a run exceeding MaxSteps: want a *StepError wrapping ErrStepLimitExceeded for 'loop', got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidateAgainst checks the Data of the event against a schema struct,
+// passed by value or by pointer. Every exported field stands for the key
+// named by its json tag, or by the field name if it has none, and fields
+// tagged with `workflow:"required"` must be present in Data. Values of
+// fields that are not strings are decoded from JSON, like the inputs of a
+// TypedWorkflow, so a key whose value does not decode into the type of its
+// field fails the validation. Fields tagged with `json:"-"` are skipped.
+//
+// All the violations are reported together, in field order.
+func (ev *BaseEvent) ValidateAgainst(v any) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot validate an event against %T, which is not a struct", v)
+	}
+	var errs []error
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		required := false
+		for _, opt := range strings.Split(field.Tag.Get("workflow"), ",") {
+			required = required || strings.TrimSpace(opt) == "required"
+		}
+		val, ok := ev.Data[key]
+		if !ok {
+			if required {
+				errs = append(errs, fmt.Errorf("missing required key %s", key))
+			}
+			continue
+		}
+		if field.Type.Kind() == reflect.String {
+			continue
+		}
+		if err := json.Unmarshal([]byte(val), reflect.New(field.Type).Interface()); err != nil {
+			errs = append(errs, fmt.Errorf("key %s holds %q, which is not a valid %s", key, val, field.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
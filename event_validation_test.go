@@ -0,0 +1,34 @@
+package workflowsgo
+
+import (
+	"strings"
+	"testing"
+)
+
+type orderSchema struct {
+	ID       string   `json:"id" workflow:"required"`
+	Quantity int      `json:"quantity" workflow:"required"`
+	Express  bool     `json:"express"`
+	Tags     []string `json:"tags"`
+	internal string
+}
+
+func TestEventValidateAgainst(t *testing.T) {
+	valid := NewBaseEvent("ship", map[string]string{"id": "A1", "quantity": "3", "tags": `["fragile"]`})
+	if err := valid.ValidateAgainst(&orderSchema{}); err != nil {
+		t.Errorf("Testing BaseEvent.ValidateAgainst with a valid event: unexpected error %v", err)
+	}
+	invalid := NewBaseEvent("ship", map[string]string{"quantity": "three", "express": "yes"})
+	err := invalid.ValidateAgainst(orderSchema{})
+	if err == nil {
+		t.Fatal("Testing BaseEvent.ValidateAgainst with a malformed event: expected an error")
+	}
+	for _, want := range []string{"missing required key id", "quantity", "express"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Testing BaseEvent.ValidateAgainst: want the error to mention %q, got %v", want, err)
+		}
+	}
+	if err := valid.ValidateAgainst("not a struct"); err == nil {
+		t.Error("Testing BaseEvent.ValidateAgainst with a non-struct schema: expected an error")
+	}
+}
@@ -0,0 +1,186 @@
+package workflowsgo
+
+import (
+	"context"
+	"sync"
+)
+
+// EventBus collects the events emitted by the steps of a run started with
+// RunWithEventBus, so that a single step can produce several events that
+// are processed independently.
+type EventBus struct {
+	mu     sync.Mutex
+	events []*BaseEvent
+}
+
+// Emit adds an event to the bus. It is safe for concurrent use.
+func (bus *EventBus) Emit(ev *BaseEvent) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.events = append(bus.events, ev)
+}
+
+// drain returns the events emitted so far, emptying the bus.
+func (bus *EventBus) drain() []*BaseEvent {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	events := bus.events
+	bus.events = nil
+	return events
+}
+
+type eventBusKey struct{}
+
+// EventBusFromContext returns the EventBus of the run that the
+// context.Context passed to a step belongs to, if the run was started with
+// RunWithEventBus.
+func EventBusFromContext(ctx context.Context) (*EventBus, bool) {
+	bus, ok := ctx.Value(eventBusKey{}).(*EventBus)
+	return bus, ok
+}
+
+// EmitEvent emits an event on the EventBus of the current run, returning
+// false if the run was not started with RunWithEventBus.
+func EmitEvent(ctx context.Context, ev *BaseEvent) bool {
+	bus, ok := EventBusFromContext(ctx)
+	if ok {
+		bus.Emit(ev)
+	}
+	return ok
+}
+
+// RunWithEventBus runs the workflow like Run, but lets steps emit any
+// number of additional events with EmitEvent.
+//
+// Events are processed one at a time, in FIFO order: after a step returns,
+// the event it returned is queued first, followed by the events it emitted
+// in emission order. A step may return nil to only emit events. Every event
+// routing to 'end' produces its own output through onOutputCallBack, and
+// the run terminates once no events are left to process. If the run stops
+// because of cancellation, MaxSteps or a missing step, the error is passed
+// to onOutputCallBack and the remaining events are discarded. The
+// compensations registered with RegisterCompensation run when the run
+// fails, as with Run.
+//
+// An event with NextSteps is replaced in the queue by a copy routed to each
+// of its steps, in order, and every branch reaching 'end' produces its own
+// output, as above, instead of the []any of Run. Runs started with an event
+// bus cannot be suspended: an event built by Suspend stops the run with an
+// error wrapping ErrSuspendNotSupported.
+func (wf *BaseWorkflow) RunWithEventBus(ctx context.Context, inputEvent *BaseEvent, wfCtx *BaseContext, onEventStartCallBack func(*BaseEvent), onEventEndCallBack func(*BaseEvent), onOutputCallBack func(any)) {
+	wf.runQueued(ctx, inputEvent, wfCtx, &fifoQueue{}, onEventStartCallBack, onEventEndCallBack, onOutputCallBack)
+}
+
+// eventQueue holds the events waiting to be processed by runQueued.
+type eventQueue interface {
+	Push(ev *BaseEvent)
+	Pop() *BaseEvent
+	Len() int
+}
+
+// fifoQueue is the eventQueue of RunWithEventBus.
+type fifoQueue struct {
+	events []*BaseEvent
+}
+
+func (q *fifoQueue) Push(ev *BaseEvent) {
+	q.events = append(q.events, ev)
+}
+
+func (q *fifoQueue) Pop() *BaseEvent {
+	if len(q.events) == 0 {
+		return nil
+	}
+	ev := q.events[0]
+	q.events = q.events[1:]
+	return ev
+}
+
+func (q *fifoQueue) Len() int {
+	return len(q.events)
+}
+
+// runQueued runs the workflow with an EventBus, processing the returned and
+// emitted events in the order in which queue dequeues them.
+func (wf *BaseWorkflow) runQueued(ctx context.Context, inputEvent *BaseEvent, wfCtx *BaseContext, queue eventQueue, onEventStartCallBack func(*BaseEvent), onEventEndCallBack func(*BaseEvent), onOutputCallBack func(any)) {
+	opts := newRunOptions(WithOnOutput(onOutputCallBack))
+	resolve := wf.newSaga(wfCtx, opts).resolve
+	wf.startTimer(opts, wfCtx)
+	if err := wf.checkStep(ctx, opts, wf.FirstStep, 0); err != nil {
+		wf.fail(opts, wf.FirstStep, err)
+		return
+	}
+	bus := &EventBus{}
+	ctx = context.WithValue(ctx, eventBusKey{}, bus)
+	wfCtx.beginRun(inputEvent, "")
+	event, err := resolve(ctx, wf.FirstStep, 1, inputEvent, wfCtx)
+	if !wf.report(opts, wf.FirstStep, event, err) {
+		return
+	}
+	wfCtx.appendTrace(wf.FirstStep)
+	stepCount := 1
+	var output any
+	emitted := bus.drain()
+	for _, ev := range emitted {
+		stamp(ev, wf.FirstStep, wfCtx)
+	}
+	if event != nil {
+		emitted = append([]*BaseEvent{event}, emitted...)
+	}
+	for _, ev := range emitted {
+		queue.Push(ev)
+	}
+	for queue.Len() > 0 {
+		event = queue.Pop()
+		if len(event.NextSteps) > 0 {
+			wfCtx.AppendEvent(event)
+			onEventStartCallBack(event)
+			for _, next := range event.NextSteps {
+				branch := event.Clone()
+				branch.NextStep, branch.NextSteps = next, nil
+				queue.Push(branch)
+			}
+			continue
+		}
+		wf.routeEmpty(event)
+		wfCtx.AppendEvent(event)
+		onEventStartCallBack(event)
+		if event.NextStep == SuspendStep {
+			wf.fail(opts, event.SourceStep, stepError(event.SourceStep, ErrSuspendNotSupported, "step %s suspended a run started with an event bus, which cannot be resumed", event.SourceStep))
+			return
+		}
+		if event.NextStep == AbortStep {
+			wf.fail(opts, event.SourceStep, abortError(event))
+			return
+		}
+		if event.NextStep == wf.terminal() {
+			wfCtx.appendTrace(event.NextStep)
+			output = wf.Output(event, wfCtx)
+			onOutputCallBack(output)
+			continue
+		}
+		if err := wf.checkStep(ctx, opts, event.NextStep, stepCount); err != nil {
+			wf.fail(opts, event.NextStep, err)
+			return
+		}
+		stepName := event.NextStep
+		next, err := resolve(ctx, stepName, stepCount+1, event, wfCtx)
+		if !wf.report(opts, stepName, next, err) {
+			return
+		}
+		wfCtx.appendTrace(stepName)
+		stepCount++
+		emitted = bus.drain()
+		for _, ev := range emitted {
+			stamp(ev, stepName, wfCtx)
+		}
+		if next != nil {
+			emitted = append([]*BaseEvent{next}, emitted...)
+		}
+		for _, ev := range emitted {
+			onEventEndCallBack(ev)
+			queue.Push(ev)
+		}
+	}
+	wf.endRun(opts, output, nil)
+}
@@ -0,0 +1,99 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunWithEventBus(t *testing.T) {
+	steps := map[string]StepFunc{
+		"split": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			for _, chunk := range strings.Fields(ev.Data["document"]) {
+				EmitEvent(ctx, NewBaseEvent("process", map[string]string{"chunk": chunk}))
+			}
+			return nil
+		},
+		"process": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": strings.ToUpper(ev.Data["chunk"])})
+		},
+	}
+	wf := NewBaseWorkflow("split", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	outputs := []any{}
+	emitted := 0
+	wf.RunWithEventBus(context.Background(), NewBaseEvent("split", map[string]string{"document": "alpha beta gamma"}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) { emitted++ }, func(out any) {
+		outputs = append(outputs, out)
+	})
+	if !slices.Equal(outputs, []any{"ALPHA", "BETA", "GAMMA"}) {
+		t.Errorf("Testing BaseWorkflow.RunWithEventBus: want %v, got %v", []any{"ALPHA", "BETA", "GAMMA"}, outputs)
+	}
+	if emitted != 3 {
+		t.Errorf("Testing BaseWorkflow.RunWithEventBus: want 3 emitted events, got %d", emitted)
+	}
+	if EmitEvent(context.Background(), NewBaseEvent("end", map[string]string{})) {
+		t.Error("Testing EmitEvent outside of RunWithEventBus: expected false")
+	}
+}
+
+func TestRunWithEventBusCompensation(t *testing.T) {
+	steps := map[string]StepFunc{
+		"reserve": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("charge", map[string]string{})
+		},
+		"charge": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("missing", map[string]string{})
+		},
+	}
+	wf := NewBaseWorkflow("reserve", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	compensated := []string{}
+	wf.RegisterCompensation("reserve", func(ev *BaseEvent, wfCtx *BaseContext) { compensated = append(compensated, "release") })
+	wf.RegisterCompensation("charge", func(ev *BaseEvent, wfCtx *BaseContext) { compensated = append(compensated, "refund") })
+	var outputs []any
+	wf.RunWithEventBus(context.Background(), NewBaseEvent("reserve", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) { outputs = append(outputs, out) })
+	if len(outputs) != 1 {
+		t.Fatalf("Testing RunWithEventBus: want a single output, got %v", outputs)
+	}
+	if err, _ := outputs[0].(error); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing RunWithEventBus with a missing step: want ErrStepNotFound, got %v", outputs)
+	}
+	if !slices.Equal(compensated, []string{"refund", "release"}) {
+		t.Errorf("Testing RunWithEventBus with a missing step: want the compensations %v, got %v", []string{"refund", "release"}, compensated)
+	}
+}
+
+func TestRunWithEventBusFanOutAndSuspend(t *testing.T) {
+	steps := map[string]StepFunc{
+		"draft": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			event := NewBaseEvent("", map[string]string{"text": "hello"})
+			event.NextSteps = []string{"summarize", "translate"}
+			return event
+		},
+		"summarize": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "summary of " + ev.Data["text"]})
+		},
+		"translate": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "translation of " + ev.Data["text"]})
+		},
+		"approve": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return Suspend("approval-1", "summarize")
+		},
+	}
+	wf := NewBaseWorkflow("draft", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	var outputs []any
+	onOutput := func(out any) { outputs = append(outputs, out) }
+	wf.RunWithEventBus(context.Background(), NewBaseEvent("draft", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, onOutput)
+	if want := []any{"summary of hello", "translation of hello"}; !slices.Equal(outputs, want) {
+		t.Errorf("Testing RunWithEventBus with NextSteps: want %v, got %v", want, outputs)
+	}
+	outputs = nil
+	wf.FirstStep = "approve"
+	wf.RunWithEventBus(context.Background(), NewBaseEvent("approve", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, onOutput)
+	if len(outputs) != 1 {
+		t.Fatalf("Testing RunWithEventBus: want a single output, got %v", outputs)
+	}
+	if err, _ := outputs[0].(error); !errors.Is(err, ErrSuspendNotSupported) {
+		t.Errorf("Testing RunWithEventBus with a suspending step: want ErrSuspendNotSupported, got %v", outputs)
+	}
+}
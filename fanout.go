@@ -0,0 +1,69 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// fanOut runs a branch for each of the NextSteps of event, concurrently,
+// each from a copy of event routed to its step and with the number of
+// steps executed so far. Once every branch has ended, their outputs are
+// passed to OnOutput as a []any, in the order of NextSteps. The OnStart,
+// OnEnd and OnError callbacks of the branches are serialized.
+func (wf *BaseWorkflow) fanOut(ctx context.Context, event *BaseEvent, wfCtx *BaseContext, stepCount int, resolve stepResolver, opts *RunOptions) {
+	outputs := make([]any, len(event.NextSteps))
+	errs := make([]error, len(event.NextSteps))
+	callbacks := opts.serialized()
+	var wg sync.WaitGroup
+	for i := range event.NextSteps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			branch := event.Clone()
+			branch.NextStep, branch.NextSteps = event.NextSteps[i], nil
+			branchOpts := *callbacks
+			branchOpts.compensate, branchOpts.onResult = nil, nil
+			branchOpts.OnOutput = func(output any) { outputs[i] = output }
+			branchOpts.onRunEnd = func(_ any, err error) { errs[i] = err }
+			wf.run(ctx, branch, wfCtx, stepCount, resolve, &branchOpts)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			outputs[i] = err
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil && opts.compensate != nil {
+		opts.compensate()
+	}
+	opts.OnOutput(outputs)
+	wf.endRun(opts, outputs, err)
+}
+
+// serialized returns a copy of opts whose OnStart, OnEnd and OnError
+// callbacks are never invoked concurrently.
+func (opts *RunOptions) serialized() *RunOptions {
+	var mu sync.Mutex
+	cp := *opts
+	cp.OnStart = func(ev *BaseEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		opts.OnStart(ev)
+	}
+	cp.OnEnd = func(ev *BaseEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		opts.OnEnd(ev)
+	}
+	if opts.OnError != nil {
+		cp.OnError = func(stepName string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			opts.OnError(stepName, err)
+		}
+	}
+	return &cp
+}
@@ -0,0 +1,76 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWorkflowFanOut(t *testing.T) {
+	steps := map[string]StepFunc{
+		"draft": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			event := NewBaseEvent("", map[string]string{"text": "hello"})
+			event.NextSteps = strings.Split(ev.Data["targets"], ",")
+			return event
+		},
+		"summarize": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "summary of " + ev.Data["text"]})
+		},
+		"translate": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("review", map[string]string{"text": "bonjour"})
+		},
+		"review": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "reviewed " + ev.Data["text"]})
+		},
+	}
+	var mu sync.Mutex
+	var ended []error
+	wf := NewBaseWorkflow("draft", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.OnRunEnd = func(output any, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ended = append(ended, err)
+	}
+	input := NewBaseEvent("draft", map[string]string{"targets": "summarize,translate"})
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	out, err := wf.RunSync(context.Background(), input, wfCtx)
+	outputs, ok := out.([]any)
+	if err != nil || !ok || !slices.Equal(outputs, []any{"summary of hello", "reviewed bonjour"}) {
+		t.Fatalf("Testing fan-out with NextSteps: want the outputs of both branches and no error, got %v and %v", out, err)
+	}
+	trace := wfCtx.ExecutionTrace()
+	slices.Sort(trace)
+	if want := []string{"draft", "end", "end", "review", "summarize", "translate"}; !slices.Equal(trace, want) {
+		t.Errorf("Testing fan-out with NextSteps: want the steps of both branches in the execution trace, got %v", trace)
+	}
+	if len(ended) != 1 || ended[0] != nil {
+		t.Errorf("Testing fan-out with NextSteps: want OnRunEnd to be called once without error, got %v", ended)
+	}
+
+	input = NewBaseEvent("draft", map[string]string{"targets": "summarize,publish"})
+	var failed []string
+	wf.RunWith(context.Background(), input, NewBaseContext(map[string]any{}, map[string]any{}),
+		WithOnOutput(func(output any) { out = output }),
+		WithOnError(func(stepName string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, stepName)
+		}),
+	)
+	outputs, _ = out.([]any)
+	if len(outputs) != 2 || outputs[0] != "summary of hello" {
+		t.Fatalf("Testing fan-out with a failing branch: want the outputs of both branches, got %v", out)
+	}
+	if err, ok := outputs[1].(error); !ok || !errors.Is(err, ErrStepNotFound) || !slices.Equal(failed, []string{"publish"}) {
+		t.Errorf("Testing fan-out with a failing branch: want ErrStepNotFound for publish, got %v and %v", outputs[1], failed)
+	}
+	if len(ended) != 2 || !errors.Is(ended[1], ErrStepNotFound) {
+		t.Errorf("Testing fan-out with a failing branch: want OnRunEnd to be called with the error of the branch, got %v", ended)
+	}
+	if out, err := wf.RunSync(context.Background(), input, NewBaseContext(map[string]any{}, map[string]any{})); out != nil || !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing RunSync with a failing branch: want ErrStepNotFound, got %v and %v", out, err)
+	}
+}
@@ -0,0 +1,178 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileContext is an implementation of GenericContext that persists its
+// Store and State to a JSON file every time they change, so that they
+// survive restarts. Values go through JSON, so after a restart they are
+// read back as the types produced by encoding/json (e.g. structs as
+// map[string]any and numbers as float64).
+//
+// Writes replace the file atomically, by renaming a temporary file written
+// in the same directory. Since the methods of GenericContext do not return
+// errors, the error of the last failed write is available through Err.
+//
+// CompressThreshold, when greater than 0, makes the values of the Store and
+// of the State whose JSON encoding is longer than that many bytes, e.g.
+// long documents, be persisted gzip-compressed. The keys of the compressed
+// values are listed in the file apart from the Store and the State, so
+// that they are decompressed transparently when the file is loaded,
+// whatever the threshold, can coexist with uncompressed ones, and cannot
+// be mistaken for the values stored by the workflow.
+type FileContext struct {
+	Path              string
+	CompressThreshold int
+
+	mu    sync.RWMutex
+	store map[string]any
+	state map[string]any
+	err   error
+}
+
+// fileContextData is the JSON representation of a FileContext.
+type fileContextData struct {
+	Store map[string]any `json:"store"`
+	State map[string]any `json:"state"`
+	// Compressed lists the keys of the compressed values, see
+	// CompressThreshold. It is omitted when no value is compressed.
+	Compressed *compressedKeys `json:"compressed,omitempty"`
+}
+
+// compressedKeys lists the keys of the compressed values of the Store and
+// of the State.
+type compressedKeys struct {
+	Store []string `json:"store,omitempty"`
+	State []string `json:"state,omitempty"`
+}
+
+// NewFileContext is a constructor that returns a FileContext persisted at
+// the given path, loading the values stored by previous runs if the file
+// exists. It returns an error if the file cannot be read or is corrupt.
+func NewFileContext(path string) (*FileContext, error) {
+	fc := &FileContext{
+		Path:  path,
+		store: map[string]any{},
+		state: map[string]any{},
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the context file %s: %w", path, err)
+	}
+	var data fileContextData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("the context file %s is corrupt: %w", path, err)
+	}
+	if data.Compressed != nil {
+		if err := decompressValues(data.Store, data.Compressed.Store); err != nil {
+			return nil, fmt.Errorf("the context file %s is corrupt: %w", path, err)
+		}
+		if err := decompressValues(data.State, data.Compressed.State); err != nil {
+			return nil, fmt.Errorf("the context file %s is corrupt: %w", path, err)
+		}
+	}
+	if data.Store != nil {
+		fc.store = data.Store
+	}
+	if data.State != nil {
+		fc.state = data.State
+	}
+	return fc, nil
+}
+
+// persist writes the Store and the State to the file. It must be called
+// while holding the write lock.
+func (fc *FileContext) persist() {
+	store, compressedStore, err := compressValues(fc.store, fc.CompressThreshold)
+	if err != nil {
+		fc.err = fmt.Errorf("could not encode the context: %w", err)
+		return
+	}
+	state, compressedState, err := compressValues(fc.state, fc.CompressThreshold)
+	if err != nil {
+		fc.err = fmt.Errorf("could not encode the context: %w", err)
+		return
+	}
+	data := fileContextData{Store: store, State: state}
+	if compressedStore != nil || compressedState != nil {
+		data.Compressed = &compressedKeys{Store: compressedStore, State: compressedState}
+	}
+	fc.err = writeFileAtomic(fc.Path, data)
+}
+
+// writeFileAtomic JSON-encodes a value and writes it to path, replacing the
+// previous content atomically.
+func writeFileAtomic(path string, val any) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("could not encode the context: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not write the context file %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write the context file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write the context file %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not write the context file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Err returns the error of the last failed write, if any.
+func (fc *FileContext) Err() error {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	return fc.err
+}
+
+// StoreValue stores a key-value pair in the Store and persists it.
+func (fc *FileContext) StoreValue(key string, val any) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.store[key] = val
+	fc.persist()
+}
+
+// GetValue fetches the value associated with a key in the Store.
+func (fc *FileContext) GetValue(key string) (val any, success bool) {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	val, success = fc.store[key]
+	return
+}
+
+// GetState fetches a copy of the State.
+func (fc *FileContext) GetState() map[string]any {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+	return maps.Clone(fc.state)
+}
+
+// SetState assigns a value to the State and persists it.
+func (fc *FileContext) SetState(state map[string]any) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.state = maps.Clone(state)
+	if fc.state == nil {
+		fc.state = map[string]any{}
+	}
+	fc.persist()
+}
@@ -0,0 +1,83 @@
+package workflowsgo
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFileContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "context.json")
+	fc, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing NewFileContext with a missing file: unexpected error %v", err)
+	}
+	var _ GenericContext = fc
+	fc.StoreValue("user", "John Doe")
+	fc.SetState(map[string]any{"iterations": 3})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fc.StoreValue("counter", i)
+		}(i)
+	}
+	wg.Wait()
+	if err := fc.Err(); err != nil {
+		t.Fatalf("Testing FileContext.StoreValue: unexpected error %v", err)
+	}
+
+	restarted, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing NewFileContext with an existing file: unexpected error %v", err)
+	}
+	if user, ok := restarted.GetValue("user"); !ok || user != "John Doe" {
+		t.Errorf("Testing NewFileContext after a restart: want 'John Doe' and true, got %v and %v", user, ok)
+	}
+	if _, ok := restarted.GetValue("counter"); !ok {
+		t.Error("Testing NewFileContext after a restart: expected the counter to be present")
+	}
+	if state := restarted.GetState(); !maps.Equal(state, map[string]any{"iterations": float64(3)}) {
+		t.Errorf("Testing NewFileContext after a restart: unexpected state %v", state)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileContext(path); err == nil {
+		t.Error("Testing NewFileContext with a corrupt file: expected an error")
+	}
+}
+
+func TestFileContextCompression(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "context.json")
+	fc, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing NewFileContext: unexpected error %v", err)
+	}
+	fc.CompressThreshold = 1024
+	document := strings.Repeat("a long document ", 1<<16)
+	fc.StoreValue("document", document)
+	fc.StoreValue("title", "short")
+	if err := fc.Err(); err != nil {
+		t.Fatalf("Testing FileContext.CompressThreshold: unexpected error %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() >= int64(len(document))/10 {
+		t.Errorf("Testing FileContext.CompressThreshold: want the file much smaller than the %d bytes document, got %v and %v", len(document), info.Size(), err)
+	}
+	restarted, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing NewFileContext with compressed values: unexpected error %v", err)
+	}
+	if val, _ := restarted.GetValue("document"); val != document {
+		t.Error("Testing NewFileContext with compressed values: the document did not round-trip")
+	}
+	if val, _ := restarted.GetValue("title"); val != "short" {
+		t.Errorf("Testing NewFileContext with mixed values: want 'short', got %v", val)
+	}
+}
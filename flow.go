@@ -0,0 +1,148 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+)
+
+// Flow builds a BaseWorkflow for simple linear or binary flows, wiring the
+// routing between the steps instead of leaving it to them:
+//
+//	wf, err := NewFlow().Step("a", fnA).Then("b", fnB).End()
+//
+// The steps of a flow route by leaving the NextStep of the events they
+// return empty: Flow fills it in with the step wired after them, while an
+// event with an explicit NextStep, e.g. routing to 'end' early, is kept as
+// it is. The wiring is also declared in the Transitions of the workflow,
+// so that it can be checked with Validate and rendered with ToMermaid.
+type Flow struct {
+	steps map[string]StepFunc
+	// order holds the names of the steps in registration order.
+	order []string
+	first string
+	// last is the step that the next call to Then or End wires from, empty
+	// after Branch.
+	last string
+	// routes holds the routing of the wired steps, by step name.
+	routes map[string]func(*BaseEvent, *BaseContext) string
+	// transitions holds the targets of the wired steps, by step name.
+	transitions map[string][]string
+	err         error
+}
+
+// NewFlow is a constructor that returns an empty Flow.
+func NewFlow() *Flow {
+	return &Flow{
+		steps:       map[string]StepFunc{},
+		routes:      map[string]func(*BaseEvent, *BaseContext) string{},
+		transitions: map[string][]string{},
+	}
+}
+
+// Step registers a step without wiring any step to it, which is how a flow
+// begins and how the targets of a Branch are declared. The first step
+// registered is the FirstStep of the workflow.
+func (f *Flow) Step(name string, fn StepFunc) *Flow {
+	if f.err != nil {
+		return f
+	}
+	if _, ok := f.steps[name]; ok {
+		f.err = fmt.Errorf("step %s is already registered", name)
+		return f
+	}
+	f.steps[name] = fn
+	f.order = append(f.order, name)
+	if f.first == "" {
+		f.first = name
+	}
+	f.last = name
+	return f
+}
+
+// Then registers a step and wires the previous step to route to it.
+func (f *Flow) Then(name string, fn StepFunc) *Flow {
+	if f.err != nil {
+		return f
+	}
+	from := f.last
+	if from == "" {
+		f.err = fmt.Errorf("cannot wire step %s: no step to route from, use Step after Branch", name)
+		return f
+	}
+	f.Step(name, fn)
+	f.wire(from, func(*BaseEvent, *BaseContext) string { return name }, name)
+	return f
+}
+
+// Branch wires the previous step to route to ifTrue when predicate is
+// satisfied by the event it returns, and to ifFalse otherwise. Both
+// targets are then registered with Step, or are 'end'.
+func (f *Flow) Branch(predicate Predicate, ifTrue, ifFalse string) *Flow {
+	if f.err != nil {
+		return f
+	}
+	if f.last == "" {
+		f.err = fmt.Errorf("cannot branch to %s or %s: no step to route from", ifTrue, ifFalse)
+		return f
+	}
+	f.wire(f.last, func(ev *BaseEvent, wfCtx *BaseContext) string {
+		if predicate(ev, wfCtx) {
+			return ifTrue
+		}
+		return ifFalse
+	}, ifTrue, ifFalse)
+	f.last = ""
+	return f
+}
+
+// End wires the previous step, if any, to route to 'end' and returns the
+// workflow, created with NewBaseWorkflow and an empty Context. An error is
+// returned if the flow registers a step twice, wires a step from nowhere or
+// does not pass Validate.
+func (f *Flow) End() (*BaseWorkflow, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.last != "" {
+		f.wire(f.last, func(*BaseEvent, *BaseContext) string { return "end" }, "end")
+	}
+	steps := map[string]StepFunc{}
+	for name, fn := range f.steps {
+		steps[name] = fn
+		if route, ok := f.routes[name]; ok {
+			steps[name] = routed(fn, route)
+		}
+	}
+	wf := NewBaseWorkflow(f.first, NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.Transitions = f.transitions
+	wf.stepOrder = f.order
+	if _, err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+// wire sets the routing of the step named from.
+func (f *Flow) wire(from string, route func(*BaseEvent, *BaseContext) string, targets ...string) {
+	if _, ok := f.routes[from]; ok {
+		f.err = fmt.Errorf("step %s is already wired", from)
+		return
+	}
+	f.routes[from] = route
+	f.transitions[from] = targets
+}
+
+// routed wraps a step of a Flow so that the events it returns with an empty
+// NextStep are routed by route.
+func routed(step StepFunc, route func(*BaseEvent, *BaseContext) string) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		result := step(ctx, ev, wfCtx)
+		if result == nil {
+			result = NewBaseEvent("", map[string]string{})
+		}
+		if result.NextStep == "" {
+			result.NextStep = route(result, wfCtx)
+		}
+		return result
+	}
+}
@@ -0,0 +1,52 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFlow(t *testing.T) {
+	appendStep := func(name string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("", map[string]string{"output": ev.Data["output"] + name})
+		}
+	}
+	wf, err := NewFlow().Step("a", appendStep("a")).Then("b", appendStep("b")).Then("c", appendStep("c")).End()
+	if err != nil {
+		t.Fatalf("Testing NewFlow: unexpected error %v", err)
+	}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("a", map[string]string{}), wfCtx)
+	if err != nil || out != "abc" || !slices.Equal(wfCtx.ExecutionTrace(), []string{"a", "b", "c", "end"}) {
+		t.Errorf("Testing a linear Flow: want 'abc' through a, b and c, got %v, %v and %v", out, err, wfCtx.ExecutionTrace())
+	}
+	if names := wf.StepNames(); !slices.Equal(names, []string{"a", "b", "c"}) {
+		t.Errorf("Testing a linear Flow: want the steps in registration order, got %v", names)
+	}
+
+	isLong := func(ev *BaseEvent, wfCtx *BaseContext) bool { return len(ev.Data["text"]) > 5 }
+	echo := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("", ev.Data)
+	}
+	label := func(text string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": text})
+		}
+	}
+	branching, err := NewFlow().Step("check", echo).Branch(isLong, "long", "short").Step("long", label("long")).Step("short", label("short")).End()
+	if err != nil {
+		t.Fatalf("Testing Flow.Branch: unexpected error %v", err)
+	}
+	for text, want := range map[string]string{"hi": "short", "hello world": "long"} {
+		if out, err := branching.RunSync(context.Background(), NewBaseEvent("check", map[string]string{"text": text}), NewBaseContext(map[string]any{}, map[string]any{})); err != nil || out != want {
+			t.Errorf("Testing Flow.Branch with %q: want %q, got %v and %v", text, want, out, err)
+		}
+	}
+	if _, err := NewFlow().Step("check", echo).Branch(isLong, "long", "short").Then("next", echo).End(); err == nil {
+		t.Error("Testing Flow.Then after Branch: expected an error")
+	}
+	if _, err := NewFlow().Step("check", echo).Branch(isLong, "long", "short").End(); err == nil {
+		t.Error("Testing Flow.End with unregistered branch targets: expected an error")
+	}
+}
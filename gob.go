@@ -0,0 +1,51 @@
+package workflowsgo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sync"
+)
+
+// gobContext is the gob representation of a BaseContext.
+type gobContext struct {
+	Store map[string]any
+	State map[string]any
+}
+
+// GobEncode implements gob.GobEncoder, encoding the Store and State of the
+// BaseContext with encoding/gob. Unlike JSON, gob round-trips the Go types
+// of the values held by the context, but the concrete types stored as
+// values, other than the basic ones, must be registered with gob.Register
+// before encoding and decoding.
+func (ctx *BaseContext) GobEncode() ([]byte, error) {
+	defer ctx.rlock()()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobContext{Store: ctx.Store, State: ctx.State}); err != nil {
+		return nil, fmt.Errorf("could not encode the context: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder, replacing the Store and State of the
+// BaseContext with the ones encoded by GobEncode.
+func (ctx *BaseContext) GobDecode(data []byte) error {
+	var decoded gobContext
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return fmt.Errorf("could not decode the context: %w", err)
+	}
+	if ctx.mu == nil {
+		ctx.mu = &sync.RWMutex{}
+	}
+	defer ctx.lock()()
+	ctx.Store = decoded.Store
+	ctx.State = decoded.State
+	if ctx.Store == nil {
+		ctx.Store = map[string]any{}
+	}
+	if ctx.State == nil {
+		ctx.State = map[string]any{}
+	}
+	ctx.expirations = nil
+	return nil
+}
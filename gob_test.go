@@ -0,0 +1,32 @@
+package workflowsgo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+type retrievedDocument struct {
+	Title string
+	Score float64
+}
+
+func TestContextGob(t *testing.T) {
+	gob.Register(retrievedDocument{})
+	ctx := NewBaseContext(map[string]any{"document": retrievedDocument{Title: "report", Score: 0.9}, "count": 3}, map[string]any{"status": "done"})
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ctx); err != nil {
+		t.Fatalf("Testing BaseContext.GobEncode: unexpected error %v", err)
+	}
+	decoded := &BaseContext{}
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatalf("Testing BaseContext.GobDecode: unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Store, ctx.Store) || !reflect.DeepEqual(decoded.GetState(), ctx.GetState()) {
+		t.Errorf("Testing BaseContext gob round-trip: want %v and %v, got %v and %v", ctx.Store, ctx.State, decoded.Store, decoded.State)
+	}
+	if val, _ := decoded.GetValue("document"); val != (retrievedDocument{Title: "report", Score: 0.9}) {
+		t.Errorf("Testing BaseContext gob round-trip: want the registered struct, got %#v", val)
+	}
+}
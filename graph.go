@@ -0,0 +1,134 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// graphNodes returns the nodes of the declared step graph, in the order in
+// which exporters render them: FirstStep, the other registered steps and
+// the undeclared transition targets in alphabetical order, and 'end'.
+func (wf *BaseWorkflow) graphNodes() []string {
+	nodes := []string{wf.FirstStep}
+	others := []string{}
+	for _, name := range sortedKeys(wf.Steps) {
+		if name != wf.FirstStep {
+			others = append(others, name)
+		}
+	}
+	for _, from := range sortedKeys(wf.Transitions) {
+		for _, to := range append([]string{from}, wf.Transitions[from]...) {
+			if to != wf.FirstStep && to != wf.terminal() && !slices.Contains(others, to) {
+				others = append(others, to)
+			}
+		}
+	}
+	slices.Sort(others)
+	nodes = append(nodes, others...)
+	if wf.FirstStep != wf.terminal() {
+		nodes = append(nodes, wf.terminal())
+	}
+	return nodes
+}
+
+// ToMermaid renders the declared Transitions of the workflow as a Mermaid
+// flowchart, from FirstStep to 'end'. Every registered step is rendered as
+// a node, even when it declares no outgoing transitions.
+func (wf *BaseWorkflow) ToMermaid() string {
+	nodes := wf.graphNodes()
+	ids := make(map[string]string, len(nodes))
+	var sb strings.Builder
+	sb.WriteString("graph TD\n")
+	for i, name := range nodes {
+		ids[name] = fmt.Sprintf("step%d", i)
+		label := strings.ReplaceAll(name, `"`, "#quot;")
+		switch name {
+		case wf.FirstStep:
+			fmt.Fprintf(&sb, "    %s([\"%s\"])\n", ids[name], label)
+		case wf.terminal():
+			fmt.Fprintf(&sb, "    %s((\"%s\"))\n", ids[name], label)
+		default:
+			fmt.Fprintf(&sb, "    %s[\"%s\"]\n", ids[name], label)
+		}
+	}
+	for _, from := range nodes {
+		for _, to := range wf.Transitions[from] {
+			fmt.Fprintf(&sb, "    %s --> %s\n", ids[from], ids[to])
+		}
+	}
+	return sb.String()
+}
+
+// dotQuote quotes a name as a DOT identifier.
+func dotQuote(name string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name) + `"`
+}
+
+// ToDOT renders the declared Transitions of the workflow as a Graphviz
+// digraph, e.g. to be piped to `dot -Tpng`. Step names are always quoted,
+// FirstStep is rendered as an ellipse, 'end' as a double circle and the
+// other steps as boxes.
+func (wf *BaseWorkflow) ToDOT() string {
+	nodes := wf.graphNodes()
+	var sb strings.Builder
+	sb.WriteString("digraph workflow {\n")
+	for _, name := range nodes {
+		shape := "box"
+		switch name {
+		case wf.FirstStep:
+			shape = "ellipse"
+		case wf.terminal():
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(&sb, "    %s [shape=%s];\n", dotQuote(name), shape)
+	}
+	for _, from := range nodes {
+		for _, to := range wf.Transitions[from] {
+			fmt.Fprintf(&sb, "    %s -> %s;\n", dotQuote(from), dotQuote(to))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// workflowDescription is the JSON document returned by ToJSON.
+type workflowDescription struct {
+	FirstStep       string              `json:"firstStep"`
+	Steps           []string            `json:"steps"`
+	Transitions     map[string][]string `json:"transitions"`
+	ReservedSteps   []string            `json:"reservedSteps"`
+	OutputKey       string              `json:"outputKey"`
+	OutputKeys      []string            `json:"outputKeys,omitempty"`
+	TerminalStep    string              `json:"terminalStep"`
+	DefaultNextStep string              `json:"defaultNextStep,omitempty"`
+}
+
+// ToJSON describes the structure of the workflow as a JSON document for
+// external tooling: its FirstStep, the names of its steps as listed by
+// StepNames, its declared Transitions, its ReservedSteps, its OutputKey
+// and TerminalStep, and its OutputKeys and DefaultNextStep when they are
+// set. The TerminalStep is always listed among the reserved steps.
+func (wf *BaseWorkflow) ToJSON() ([]byte, error) {
+	transitions := map[string][]string{}
+	for from, targets := range wf.Transitions {
+		transitions[from] = slices.Clone(targets)
+	}
+	reserved := []string{wf.terminal()}
+	for _, name := range wf.ReservedSteps {
+		if !slices.Contains(reserved, name) {
+			reserved = append(reserved, name)
+		}
+	}
+	return json.Marshal(workflowDescription{
+		FirstStep:       wf.FirstStep,
+		Steps:           wf.StepNames(),
+		Transitions:     transitions,
+		ReservedSteps:   reserved,
+		OutputKey:       wf.outputKey(),
+		OutputKeys:      wf.OutputKeys,
+		TerminalStep:    wf.terminal(),
+		DefaultNextStep: wf.DefaultNextStep,
+	})
+}
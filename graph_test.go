@@ -0,0 +1,63 @@
+package workflowsgo
+
+import "testing"
+
+func TestToMermaid(t *testing.T) {
+	steps := map[string]StepFunc{"retrieve": mockStep, "generate": mockStep, "critique": mockStep, "audit": mockStep}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.Transitions = map[string][]string{
+		"retrieve": {"generate"},
+		"generate": {"critique"},
+		"critique": {"generate", "end"},
+	}
+	want := `graph TD
+    step0(["retrieve"])
+    step1["audit"]
+    step2["critique"]
+    step3["generate"]
+    step4(("end"))
+    step0 --> step3
+    step2 --> step3
+    step2 --> step4
+    step3 --> step2
+`
+	if got := wf.ToMermaid(); got != want {
+		t.Errorf("Testing BaseWorkflow.ToMermaid: want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestToDOT(t *testing.T) {
+	steps := map[string]StepFunc{"load docs": mockStep, `say "hi"`: mockStep, `C:\temp`: mockStep}
+	wf := NewBaseWorkflow("load docs", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.Transitions = map[string][]string{
+		"load docs": {`say "hi"`},
+		`say "hi"`:  {"end"},
+	}
+	want := `digraph workflow {
+    "load docs" [shape=ellipse];
+    "C:\\temp" [shape=box];
+    "say \"hi\"" [shape=box];
+    "end" [shape=doublecircle];
+    "load docs" -> "say \"hi\"";
+    "say \"hi\"" -> "end";
+}
+`
+	if got := wf.ToDOT(); got != want {
+		t.Errorf("Testing BaseWorkflow.ToDOT: want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"retrieve": mockStep, "generate": mockStep})
+	wf.AddStep("cite", mockStep)
+	wf.Transitions = map[string][]string{"retrieve": {"generate"}, "generate": {"cite"}, "cite": {"end"}}
+	wf.ReservedSteps = append(wf.ReservedSteps, "retry")
+	data, err := wf.ToJSON()
+	if err != nil {
+		t.Fatalf("Testing BaseWorkflow.ToJSON: unexpected error %v", err)
+	}
+	want := `{"firstStep":"retrieve","steps":["generate","retrieve","cite"],"transitions":{"cite":["end"],"generate":["cite"],"retrieve":["generate"]},"reservedSteps":["end","retry"],"outputKey":"output","terminalStep":"end"}`
+	if string(data) != want {
+		t.Errorf("Testing BaseWorkflow.ToJSON: want\n%s\ngot\n%s", want, data)
+	}
+}
@@ -0,0 +1,52 @@
+package workflowsgo
+
+// AppendEvent records an event in the history of the BaseContext. The event
+// is copied, so that later mutations of its Data do not alter the history.
+func (ctx *BaseContext) AppendEvent(ev *BaseEvent) {
+	defer ctx.lock()()
+	ctx.history = append(ctx.history, ev.Clone())
+}
+
+// History returns the events recorded in the BaseContext, in order. Run
+// records the input event and every event it processes, up to the one
+// routing to 'end', after clearing the events of previous runs.
+func (ctx *BaseContext) History() []*BaseEvent {
+	defer ctx.rlock()()
+	history := make([]*BaseEvent, len(ctx.history))
+	for i, ev := range ctx.history {
+		history[i] = ev.Clone()
+	}
+	return history
+}
+
+// beginRun clears the trace, the history and the timeline of the
+// BaseContext at the beginning of a run, recording the input event and the run ID, which is
+// generated if runID is empty.
+func (ctx *BaseContext) beginRun(inputEvent *BaseEvent, runID string) {
+	ctx.resetTrace()
+	if runID == "" {
+		runID = newRunID()
+	}
+	defer ctx.lock()()
+	ctx.State[RunIDKey] = runID
+	ctx.history = []*BaseEvent{inputEvent.Clone()}
+	ctx.timeline = nil
+}
+
+// Replay feeds a recorded history, as returned by BaseContext.History, to
+// handler for debugging, in order and without invoking any step: the input
+// event of the run comes first, followed by the events that reached the
+// OnStart callback of the run. Each event is copied before being passed to
+// handler.
+//
+// Replay stops at the recorded end: after the first event routing to
+// TerminalStep or, for a run that failed, was suspended or was recorded
+// only in part, after the last event of the history.
+func (wf *BaseWorkflow) Replay(history []*BaseEvent, handler func(*BaseEvent)) {
+	for _, ev := range history {
+		handler(ev.Clone())
+		if ev != nil && ev.NextStep == wf.terminal() {
+			return
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	steps := map[string]StepFunc{
+		"retrieve": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("rerank", map[string]string{"docs": "a,b"})
+		},
+		"rerank": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			ev.Data["docs"] = "mutated"
+			return NewBaseEvent("generate", map[string]string{"docs": "b,a"})
+		},
+		"generate": mockStep,
+	}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	for i := 0; i < 2; i++ {
+		wf.Run(context.Background(), NewBaseEvent("retrieve", map[string]string{"query": "q"}), wfCtx, func(*BaseEvent) {}, func(*BaseEvent) {}, func(any) {}, nil)
+	}
+	history := wfCtx.History()
+	nextSteps := []string{}
+	for _, ev := range history {
+		nextSteps = append(nextSteps, ev.NextStep)
+	}
+	if !slices.Equal(nextSteps, []string{"retrieve", "rerank", "generate", "end"}) {
+		t.Fatalf("Testing BaseContext.History: unexpected events %v", nextSteps)
+	}
+	if history[1].Data["docs"] != "a,b" {
+		t.Errorf("Testing BaseContext.History: expected the recorded event not to be mutated, got %v", history[1].Data)
+	}
+	history[3].Data["output"] = "changed"
+	if wfCtx.History()[3].Data["output"] != "hello world" {
+		t.Error("Testing BaseContext.History: expected the returned events to be copies")
+	}
+}
+
+func TestWorkflowReplay(t *testing.T) {
+	steps := map[string]StepFunc{
+		"retrieve": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("generate", map[string]string{"docs": "a,b"})
+		},
+		"generate": mockStep,
+	}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	input := NewBaseEvent("retrieve", map[string]string{"query": "q"})
+	recorded := []string{input.NextStep}
+	wf.Run(context.Background(), input, wfCtx, func(ev *BaseEvent) { recorded = append(recorded, ev.NextStep) }, func(*BaseEvent) {}, func(any) {}, nil)
+	replayed := []string{}
+	history := append(wfCtx.History(), NewBaseEvent("retrieve", map[string]string{}))
+	wf.Replay(history, func(ev *BaseEvent) { replayed = append(replayed, ev.NextStep) })
+	if !slices.Equal(replayed, recorded) {
+		t.Errorf("Testing BaseWorkflow.Replay: want %v, got %v", recorded, replayed)
+	}
+	replayed = nil
+	wf.Replay(history[:2], func(ev *BaseEvent) { replayed = append(replayed, ev.NextStep) })
+	if !slices.Equal(replayed, []string{"retrieve", "generate"}) {
+		t.Errorf("Testing BaseWorkflow.Replay with a history that does not reach 'end': want %v, got %v", []string{"retrieve", "generate"}, replayed)
+	}
+}
@@ -0,0 +1,60 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// httpRunRequest is the JSON body accepted by HTTPHandler.
+type httpRunRequest struct {
+	Event *BaseEvent     `json:"event"`
+	Store map[string]any `json:"store"`
+	State map[string]any `json:"state"`
+}
+
+// writeJSON writes a value as the JSON body of a response.
+func writeJSON(w http.ResponseWriter, status int, val any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(val)
+}
+
+// HTTPHandler exposes a workflow as an HTTP endpoint. It accepts POST
+// requests whose JSON body holds the input event under "event" and,
+// optionally, the initial Store and State of the context under "store" and
+// "state"; it then runs the workflow with RunSync, bound to the context of
+// the request, and responds with the output as {"output": ...}.
+//
+// Malformed bodies are answered with 400 Bad Request and failed runs with
+// 500 Internal Server Error, both with the error message as {"error": ...}.
+func HTTPHandler(wf *BaseWorkflow) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": fmt.Sprintf("method %s is not allowed", r.Method)})
+			return
+		}
+		var req httpRunRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("malformed request body: %v", err)})
+			return
+		}
+		if req.Event == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "malformed request body: missing event"})
+			return
+		}
+		if req.Store == nil {
+			req.Store = map[string]any{}
+		}
+		if req.State == nil {
+			req.State = map[string]any{}
+		}
+		output, err := wf.RunSync(r.Context(), req.Event, NewBaseContext(req.Store, req.State))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"output": output})
+	})
+}
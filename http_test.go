@@ -0,0 +1,65 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	steps := map[string]StepFunc{
+		"greet": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			greeting, _ := wfCtx.GetValue("greeting")
+			if greeting == nil {
+				return NewBaseEvent("missing", map[string]string{})
+			}
+			return NewBaseEvent("end", map[string]string{"output": greeting.(string) + " " + ev.Data["name"]})
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/run", HTTPHandler(NewBaseWorkflow("greet", NewBaseContext(map[string]any{}, map[string]any{}), steps)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var tests = []struct {
+		body   string
+		status int
+		key    string
+		want   string
+	}{
+		{`{"event": {"nextStep": "greet", "data": {"name": "world"}}, "store": {"greeting": "hello"}}`, http.StatusOK, "output", "hello world"},
+		{`{"event": {"nextStep": "greet", "data": {"name": "world"}}}`, http.StatusInternalServerError, "error", "step missing does not exist"},
+		{`{"event": `, http.StatusBadRequest, "error", "malformed request body"},
+		{`{}`, http.StatusBadRequest, "error", "missing event"},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(server.URL+"/run", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]any
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Testing HTTPHandler with body %s: could not decode the response: %v", tt.body, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("Testing HTTPHandler with body %s: want status %d, got %d", tt.body, tt.status, resp.StatusCode)
+		}
+		if val, _ := body[tt.key].(string); !strings.Contains(val, tt.want) {
+			t.Errorf("Testing HTTPHandler with body %s: want %s containing %q, got %v", tt.body, tt.key, tt.want, body)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Testing HTTPHandler with a GET request: want status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
@@ -0,0 +1,60 @@
+// gzipjson encodes the values persisted by the contexts of workflows-go as
+// JSON, gzip-compressed when they are long.
+//
+// An encoded value is either a JSON document or the gzip stream of one. Every
+// gzip stream starts with the same two bytes, which cannot start a JSON
+// document, so that Decode tells them apart: compressed and uncompressed
+// values can coexist, and are decoded whatever the threshold they were
+// encoded with.
+package gzipjson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+)
+
+// magic starts every gzip stream, and no JSON document.
+var magic = []byte{0x1f, 0x8b}
+
+// Encode returns the JSON encoding of val, gzip-compressed if it is longer
+// than threshold bytes. A threshold of 0 or less disables compression.
+func Encode(val any, threshold int) ([]byte, error) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 || len(b) <= threshold {
+		return b, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Compressed reports whether b, as returned by Encode, is compressed.
+func Compressed(b []byte) bool {
+	return bytes.HasPrefix(b, magic)
+}
+
+// Decode decodes b, as returned by Encode, into dst, decompressing it
+// first if needed.
+func Decode(b []byte, dst any) error {
+	if Compressed(b) {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		if b, err = io.ReadAll(zr); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(b, dst)
+}
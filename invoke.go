@@ -0,0 +1,38 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// InvokeStep executes a single step by name with a JSON input, e.g. for a
+// CLI listing the steps with StepNames and running one of them: dataJSON
+// is decoded as a BaseEvent, whose NextStep defaults to name, the step is
+// executed with TakeStepE in the Context of the workflow (or in an empty
+// one, if it has none), and the event it returns is encoded as JSON.
+//
+// An error is returned if dataJSON is not a valid event, if the step does
+// not exist (wrapping ErrStepNotFound) or if the returned event cannot be
+// encoded.
+func (wf *BaseWorkflow) InvokeStep(ctx context.Context, name string, dataJSON []byte) ([]byte, error) {
+	var ev BaseEvent
+	if err := json.Unmarshal(dataJSON, &ev); err != nil {
+		return nil, fmt.Errorf("invalid input event for step %s: %w", name, err)
+	}
+	if ev.NextStep == "" {
+		ev.NextStep = name
+	}
+	if ev.Data == nil {
+		ev.Data = map[string]string{}
+	}
+	wfCtx := wf.Context
+	if wfCtx == nil {
+		wfCtx = NewBaseContext(map[string]any{}, map[string]any{})
+	}
+	event, err := wf.TakeStepE(ctx, name, &ev, wfCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(event)
+}
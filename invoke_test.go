@@ -0,0 +1,26 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWorkflowInvokeStep(t *testing.T) {
+	steps := map[string]StepFunc{
+		"greet": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "hello " + ev.Data["name"]})
+		},
+	}
+	wf := NewBaseWorkflow("greet", nil, steps)
+	out, err := wf.InvokeStep(context.Background(), "greet", []byte(`{"data": {"name": "Ada"}}`))
+	if want := `{"nextStep":"end","data":{"output":"hello Ada"}}`; err != nil || string(out) != want {
+		t.Errorf("Testing BaseWorkflow.InvokeStep: want %s and no error, got %s and %v", want, out, err)
+	}
+	if _, err := wf.InvokeStep(context.Background(), "missing", []byte(`{}`)); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing BaseWorkflow.InvokeStep with an unknown step: want ErrStepNotFound, got %v", err)
+	}
+	if _, err := wf.InvokeStep(context.Background(), "greet", []byte(`not json`)); err == nil {
+		t.Error("Testing BaseWorkflow.InvokeStep with malformed JSON: expected an error")
+	}
+}
@@ -0,0 +1,76 @@
+package workflowsgo
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithStrictKeyTypes is a ContextOption that makes StoreValue and
+// StoreValueWithTTL panic when a value does not match the type registered
+// for its key with RegisterKeyType. Without it, they store such values
+// unchecked, and only StoreValueChecked rejects them.
+func WithStrictKeyTypes() ContextOption {
+	return func(ctx *BaseContext) {
+		ctx.strictTypes = true
+	}
+}
+
+// RegisterKeyType registers the type of the values stored under key, which
+// StoreValueChecked, and StoreValue in strict mode, enforce: a value
+// matches if its type is assignable to typ, and nil matches the types that
+// can be nil. Registering a nil type removes the constraint. Enforcement is
+// opt-in: keys without a registered type accept any value.
+func (ctx *BaseContext) RegisterKeyType(key string, typ reflect.Type) {
+	defer ctx.lock()()
+	if typ == nil {
+		delete(ctx.keyTypes, key)
+		return
+	}
+	if ctx.keyTypes == nil {
+		ctx.keyTypes = map[string]reflect.Type{}
+	}
+	ctx.keyTypes[key] = typ
+}
+
+// StoreValueChecked works like StoreValue, but returns an error instead of
+// storing a value that does not match the type registered for its key.
+func (ctx *BaseContext) StoreValueChecked(key string, val any) error {
+	unlock := ctx.rlock()
+	err := ctx.checkType(key, val)
+	unlock()
+	if err != nil {
+		return err
+	}
+	ctx.StoreValue(key, val)
+	return nil
+}
+
+// checkType returns an error if val does not match the type registered for
+// key. It must be called while holding a lock.
+func (ctx *BaseContext) checkType(key string, val any) error {
+	typ, ok := ctx.keyTypes[key]
+	if !ok {
+		return nil
+	}
+	if val == nil {
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+			return nil
+		}
+	} else if reflect.TypeOf(val).AssignableTo(typ) {
+		return nil
+	}
+	return fmt.Errorf("cannot store a value of type %T under %s, which holds values of type %s", val, key, typ)
+}
+
+// enforceType panics in strict mode if val does not match the type
+// registered for key.
+func (ctx *BaseContext) enforceType(key string, val any) {
+	unlock := ctx.rlock()
+	err := ctx.checkType(key, val)
+	strict := ctx.strictTypes
+	unlock()
+	if strict && err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,38 @@
+package workflowsgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterKeyType(t *testing.T) {
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	wfCtx.RegisterKeyType("count", reflect.TypeOf(0))
+	if err := wfCtx.StoreValueChecked("count", "three"); err == nil {
+		t.Error("Testing BaseContext.StoreValueChecked with a string under an int key: expected an error")
+	}
+	if _, ok := wfCtx.GetValue("count"); ok {
+		t.Error("Testing BaseContext.StoreValueChecked: the rejected value should not be stored")
+	}
+	if err := wfCtx.StoreValueChecked("count", 3); err != nil {
+		t.Errorf("Testing BaseContext.StoreValueChecked with an int: unexpected error %v", err)
+	}
+	if err := wfCtx.StoreValueChecked("label", "anything"); err != nil {
+		t.Errorf("Testing BaseContext.StoreValueChecked with an unregistered key: unexpected error %v", err)
+	}
+
+	strict := NewBaseContext(map[string]any{}, map[string]any{}, WithStrictKeyTypes())
+	strict.RegisterKeyType("count", reflect.TypeOf(0))
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Testing StoreValue in strict mode with a mismatched type: expected a panic")
+			}
+		}()
+		strict.StoreValue("count", "three")
+	}()
+	strict.StoreValue("count", 3)
+	if val, _ := strict.GetValue("count"); val != 3 {
+		t.Errorf("Testing StoreValue in strict mode after a panic: want 3, got %v", val)
+	}
+}
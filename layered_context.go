@@ -0,0 +1,131 @@
+package workflowsgo
+
+import "sync"
+
+// WritePolicy selects how a LayeredContext propagates writes to its layers.
+type WritePolicy int
+
+const (
+	// WriteThrough writes every value to all the layers right away.
+	WriteThrough WritePolicy = iota
+	// WriteBack writes values to the first layer only, propagating them to
+	// the other layers when LayeredContext.Flush is called.
+	WriteBack
+)
+
+// LayeredContext is an implementation of GenericContext composed of an
+// ordered list of backing contexts, e.g. a fast in-memory BaseContext in
+// front of a persistent FileContext or RedisContext, acting as a
+// read-through cache.
+//
+// GetValue reads from the first layer holding the key, copying the value
+// to the layers that come before it, so that the next read is served by
+// the first layer. StoreValue and SetState follow the WritePolicy: with
+// WriteThrough they write to all the layers, with WriteBack to the first
+// layer only, until Flush. GetState reads the State of the first layer.
+type LayeredContext struct {
+	Layers []GenericContext
+	Policy WritePolicy
+
+	mu         sync.Mutex
+	dirty      map[string]bool
+	dirtyState bool
+}
+
+// NewLayeredContext is a constructor that returns a LayeredContext over the
+// given layers, from the first to be read to the last, with the given
+// write policy.
+func NewLayeredContext(policy WritePolicy, layers ...GenericContext) *LayeredContext {
+	return &LayeredContext{
+		Layers: layers,
+		Policy: policy,
+		dirty:  map[string]bool{},
+	}
+}
+
+// StoreValue stores a key-value pair according to the WritePolicy.
+func (lc *LayeredContext) StoreValue(key string, val any) {
+	if len(lc.Layers) == 0 {
+		return
+	}
+	if lc.Policy == WriteBack {
+		lc.Layers[0].StoreValue(key, val)
+		lc.mu.Lock()
+		lc.dirty[key] = true
+		lc.mu.Unlock()
+		return
+	}
+	for _, layer := range lc.Layers {
+		layer.StoreValue(key, val)
+	}
+}
+
+// GetValue fetches the value associated with a key from the first layer
+// holding it, populating the layers that come before it.
+func (lc *LayeredContext) GetValue(key string) (any, bool) {
+	for i, layer := range lc.Layers {
+		val, ok := layer.GetValue(key)
+		if !ok {
+			continue
+		}
+		for _, earlier := range lc.Layers[:i] {
+			earlier.StoreValue(key, val)
+		}
+		return val, true
+	}
+	return nil, false
+}
+
+// GetState returns the State of the first layer.
+func (lc *LayeredContext) GetState() map[string]any {
+	if len(lc.Layers) == 0 {
+		return map[string]any{}
+	}
+	return lc.Layers[0].GetState()
+}
+
+// SetState sets the State according to the WritePolicy.
+func (lc *LayeredContext) SetState(state map[string]any) {
+	if len(lc.Layers) == 0 {
+		return
+	}
+	if lc.Policy == WriteBack {
+		lc.Layers[0].SetState(state)
+		lc.mu.Lock()
+		lc.dirtyState = true
+		lc.mu.Unlock()
+		return
+	}
+	for _, layer := range lc.Layers {
+		layer.SetState(state)
+	}
+}
+
+// Flush propagates the values and the State written to the first layer
+// since the last Flush to the other layers. It has no effect with
+// WriteThrough.
+func (lc *LayeredContext) Flush() {
+	lc.mu.Lock()
+	dirty, dirtyState := lc.dirty, lc.dirtyState
+	lc.dirty, lc.dirtyState = map[string]bool{}, false
+	lc.mu.Unlock()
+	if len(lc.Layers) < 2 {
+		return
+	}
+	first, rest := lc.Layers[0], lc.Layers[1:]
+	for _, key := range sortedKeys(dirty) {
+		val, ok := first.GetValue(key)
+		if !ok {
+			continue
+		}
+		for _, layer := range rest {
+			layer.StoreValue(key, val)
+		}
+	}
+	if dirtyState {
+		state := first.GetState()
+		for _, layer := range rest {
+			layer.SetState(state)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package workflowsgo
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLayeredContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "context.json")
+	file, err := NewFileContext(path)
+	if err != nil {
+		t.Fatalf("Testing LayeredContext: could not create the file context: %v", err)
+	}
+	file.StoreValue("document", "persisted")
+	memory := NewBaseContext(map[string]any{}, map[string]any{})
+	lc := NewLayeredContext(WriteThrough, memory, file)
+	var _ GenericContext = lc
+	if _, ok := memory.GetValue("document"); ok {
+		t.Fatal("Testing LayeredContext: expected the value to be stored in the file only")
+	}
+	if val, ok := lc.GetValue("document"); !ok || val != "persisted" {
+		t.Errorf("Testing LayeredContext.GetValue: want 'persisted', got %v", val)
+	}
+	if val, ok := memory.GetValue("document"); !ok || val != "persisted" {
+		t.Errorf("Testing LayeredContext.GetValue: want the value to be cached in memory, got %v", val)
+	}
+	lc.StoreValue("answer", "42")
+	if val, _ := file.GetValue("answer"); val != "42" {
+		t.Errorf("Testing LayeredContext.StoreValue with WriteThrough: want the value in the file, got %v", val)
+	}
+
+	lc.Policy = WriteBack
+	lc.StoreValue("answer", "43")
+	if val, _ := file.GetValue("answer"); val != "42" {
+		t.Errorf("Testing LayeredContext.StoreValue with WriteBack: want the file to be unchanged before Flush, got %v", val)
+	}
+	lc.Flush()
+	if val, _ := file.GetValue("answer"); val != "43" {
+		t.Errorf("Testing LayeredContext.Flush: want the value in the file, got %v", val)
+	}
+}
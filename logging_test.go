@@ -0,0 +1,46 @@
+package workflowsgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	steps := map[string]StepFunc{
+		"route": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("missing", map[string]string{})
+		},
+	}
+	wf := NewBaseWorkflow("route", NewBaseContext(map[string]any{}, map[string]any{}), steps, WithLogger(logger))
+	wf.Run(context.Background(), NewBaseEvent("route", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(any) {}, nil)
+	var tests = []struct {
+		level     string
+		msg       string
+		step      string
+		stepIndex float64
+	}{
+		{"DEBUG", "step started", "route", 1},
+		{"INFO", "event emitted", "route", 1},
+		{"DEBUG", "step started", "missing", 2},
+		{"ERROR", "step failed", "missing", 2},
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(tests) {
+		t.Fatalf("Testing BaseWorkflow.Logger: want %d records, got %d:\n%s", len(tests), len(lines), buf.String())
+	}
+	for i, tt := range tests {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+			t.Fatalf("Testing BaseWorkflow.Logger: could not decode record %s: %v", lines[i], err)
+		}
+		if record["level"] != tt.level || record["msg"] != tt.msg || record["step"] != tt.step || record["stepIndex"] != tt.stepIndex {
+			t.Errorf("Testing BaseWorkflow.Logger: want %v, got %v", tt, record)
+		}
+	}
+}
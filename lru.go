@@ -0,0 +1,65 @@
+package workflowsgo
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache is a cache evicting the least recently used entry once it holds
+// maxEntries of them, or never if maxEntries is 0 or less. It is safe for
+// concurrent use.
+type lruCache[V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// lruEntry is the value of the elements of lruCache.order.
+type lruEntry[V any] struct {
+	key string
+	val V
+}
+
+func newLRUCache[V any](maxEntries int) *lruCache[V] {
+	return &lruCache[V]{maxEntries: maxEntries, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get returns the value cached under key, marking it as the most recently
+// used.
+func (c *lruCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[V]).val, true
+}
+
+// add caches val under key as the most recently used entry, evicting the
+// least recently used one if the cache is full.
+func (c *lruCache[V]) add(key string, val V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry[V]).val = val
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, val: val})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// len returns the number of cached entries.
+func (c *lruCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
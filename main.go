@@ -6,8 +6,16 @@
 package workflowsgo
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 // GenericEvent is an interface that must be implemented by all structs
@@ -18,10 +26,40 @@ type GenericEvent interface {
 	Get(string) (any, bool)
 }
 
+// GetTyped fetches the value stored under a key within any GenericEvent and asserts it to type T, returning the zero value of T and false if the key is missing or if the value is not of type T.
+func GetTyped[T any](ev GenericEvent, key string) (T, bool) {
+	var zero T
+	val, ok := ev.Get(key)
+	if !ok {
+		return zero, false
+	}
+	typed, ok := val.(T)
+	if !ok {
+		return zero, false
+	}
+	return typed, true
+}
+
 // BaseEvent is a base implementation of the GenericEvent: it comes with NextStep (string) and Data (map) as attributes where the key information is stored.
 type BaseEvent struct {
-	NextStep string
-	Data     map[string]string
+	NextStep string            `json:"nextStep"`
+	Data     map[string]string `json:"data"`
+	// Priority orders the events queued by RunWithPriority, higher values
+	// being processed first. It is ignored by the other runners.
+	Priority int `json:"priority,omitempty"`
+	// CreatedAt is the time at which the event was emitted and SourceStep
+	// the name of the step that emitted it. They are set by the runners on
+	// the events emitted by steps, and are left empty on events built
+	// outside of a run.
+	CreatedAt  time.Time `json:"createdAt"`
+	SourceStep string    `json:"sourceStep,omitempty"`
+	// IdempotencyKey optionally identifies the event across deliveries,
+	// so that the steps wrapped with Dedup process it once.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// NextSteps, when not empty, makes Run fan out into a concurrent
+	// branch for each of the steps it names, instead of going on with
+	// NextStep, see Run.
+	NextSteps []string `json:"nextSteps,omitempty"`
 }
 
 // Get is a method of BaseEvent that fetches data stored within an BaseEvent.Data, and returns that data.
@@ -38,6 +76,26 @@ func NewBaseEvent(followingStep string, data map[string]string) *BaseEvent {
 	}
 }
 
+// TypedEvent is a generic implementation of the GenericEvent: it works like BaseEvent, but its Data can hold values of any type T, so that rich values (structs, numbers, slices) can be stored without converting them to strings.
+type TypedEvent[T any] struct {
+	NextStep string
+	Data     map[string]T
+}
+
+// Get is a method of TypedEvent that fetches data stored within an TypedEvent.Data, and returns that data.
+func (ev *TypedEvent[T]) Get(key string) (val any, success bool) {
+	val, success = ev.Data[key]
+	return
+}
+
+// NewTypedEvent is a constructor function that, given a string representing the name of the following step and a map containing data, returns a TypedEvent.
+func NewTypedEvent[T any](followingStep string, data map[string]T) *TypedEvent[T] {
+	return &TypedEvent[T]{
+		Data:     data,
+		NextStep: followingStep,
+	}
+}
+
 // GenericContext is the interface representing a context, i.e. a storage
 // space that is aimed at allowing persistency and stefulness for
 // workflow executions. All structs representing a workflow context
@@ -60,116 +118,994 @@ type GenericContext interface {
 // BaseContext is a base implementation of the GenericContext implementation.
 // It comes with a Store (persistent storage), which is a map
 // and a State (stateful execution) which also is a map.
+//
+// A BaseContext created with NewBaseContext is safe for concurrent use
+// through its methods: accessing Store and State directly bypasses the
+// synchronization, and so does mutating the map returned by GetState.
 type BaseContext struct {
 	Store map[string]any
 	State map[string]any
+	mu    *sync.RWMutex
+
+	// expirations holds the expiry time of the Store keys set with StoreValueWithTTL.
+	expirations map[string]time.Time
+	// clock is the source of time of the BaseContext.
+	clock Clock
+	// history holds the events recorded with AppendEvent.
+	history []*BaseEvent
+	// snapshots holds the snapshots taken with Snapshot, the latest last.
+	snapshots []contextSnapshot
+	// snapshotSeq numbers the tokens returned by Snapshot.
+	snapshotSeq int
+	// parent is the context GetValue reads through to, for the contexts
+	// created with ChildContext.
+	parent *BaseContext
+	// watchers holds the callbacks registered with Watch, by key.
+	watchers map[string][]func(old, new any)
+	// rng is the source of randomness returned by ContextRand.
+	rng *rand.Rand
+	// keyTypes holds the types registered with RegisterKeyType, by key.
+	keyTypes map[string]reflect.Type
+	// strictTypes makes StoreValue panic on type mismatches, see
+	// WithStrictKeyTypes.
+	strictTypes bool
+	// changeLog, if set, records the mutations of the context, see
+	// WithChangeLog.
+	changeLog *changeLog
+	// timeline holds the spans of the steps executed by the last run, see
+	// Timeline.
+	timeline []StepSpan
+}
+
+// ContextOption configures a BaseContext when it is created with
+// NewBaseContext.
+type ContextOption func(*BaseContext)
+
+// WithClock is a ContextOption that sets the Clock used by the BaseContext
+// and by the step wrappers that receive it.
+func WithClock(clock Clock) ContextOption {
+	return func(ctx *BaseContext) {
+		ctx.clock = clock
+	}
 }
 
 // NewBaseContext is a constructor that, starting from a map representing
-// the Store and one representing the State, returns a BaseContext.
-func NewBaseContext(store, state map[string]any) *BaseContext {
-	return &BaseContext{
+// the Store and one representing the State, returns a BaseContext,
+// configured by the given options, in order.
+func NewBaseContext(store, state map[string]any, opts ...ContextOption) *BaseContext {
+	ctx := &BaseContext{
 		Store: store,
 		State: state,
+		mu:    &sync.RWMutex{},
 	}
+	for _, opt := range opts {
+		opt(ctx)
+	}
+	return ctx
 }
 
-// StoreValue stores a key-value pair in BaseContext.Store.
+// Clock returns the Clock used by the BaseContext, which is a RealClock
+// unless another one was set with WithClock.
+func (ctx *BaseContext) Clock() Clock {
+	if ctx == nil || ctx.clock == nil {
+		return RealClock{}
+	}
+	return ctx.clock
+}
+
+// lock acquires the write lock of the BaseContext, if it has one, and
+// returns the function that releases it.
+func (ctx *BaseContext) lock() func() {
+	if ctx.mu == nil {
+		return func() {}
+	}
+	ctx.mu.Lock()
+	return ctx.mu.Unlock
+}
+
+// rlock acquires the read lock of the BaseContext, if it has one, and
+// returns the function that releases it.
+func (ctx *BaseContext) rlock() func() {
+	if ctx.mu == nil {
+		return func() {}
+	}
+	ctx.mu.RLock()
+	return ctx.mu.RUnlock
+}
+
+// StoreValue stores a key-value pair in BaseContext.Store. The value does
+// not expire, even if the key was previously stored with StoreValueWithTTL.
 func (ctx *BaseContext) StoreValue(key string, val any) {
+	ctx.enforceType(key, val)
+	unlock := ctx.lock()
+	old := ctx.current(key)
 	ctx.Store[key] = val
+	delete(ctx.expirations, key)
+	watchers := ctx.watchers[key]
+	unlock()
+	ctx.changed(key, watchers, old, val)
+}
+
+// StoreValueWithTTL stores a key-value pair in BaseContext.Store that
+// expires once ttl has elapsed: from then on, GetValue reports the key as
+// missing. Expired values are evicted lazily, when they are read.
+func (ctx *BaseContext) StoreValueWithTTL(key string, val any, ttl time.Duration) {
+	ctx.enforceType(key, val)
+	unlock := ctx.lock()
+	old := ctx.current(key)
+	ctx.Store[key] = val
+	if ctx.expirations == nil {
+		ctx.expirations = map[string]time.Time{}
+	}
+	ctx.expirations[key] = ctx.Clock().Now().Add(ttl)
+	watchers := ctx.watchers[key]
+	unlock()
+	ctx.changed(key, watchers, old, val)
+}
+
+// expired reports whether a key stored with StoreValueWithTTL has expired.
+// It must be called while holding a lock.
+func (ctx *BaseContext) expired(key string) bool {
+	expiry, ok := ctx.expirations[key]
+	return ok && !ctx.Clock().Now().Before(expiry)
 }
 
 // GetValue fetches the value associated with a key in BaseContext.Store.
+// For a context created with ChildContext, keys missing from its Store are
+// read from the parent.
 func (ctx *BaseContext) GetValue(key string) (val any, success bool) {
+	val, success = ctx.getLocalValue(key)
+	if !success && ctx.parent != nil {
+		return ctx.parent.GetValue(key)
+	}
+	return val, success
+}
+
+// getLocalValue fetches the value associated with a key in
+// BaseContext.Store, evicting it if it has expired.
+func (ctx *BaseContext) getLocalValue(key string) (val any, success bool) {
+	unlock := ctx.rlock()
+	val, success = ctx.Store[key]
+	expired := ctx.expired(key)
+	unlock()
+	if !expired {
+		return val, success
+	}
+	defer ctx.lock()()
+	if ctx.expired(key) {
+		delete(ctx.Store, key)
+		delete(ctx.expirations, key)
+		return nil, false
+	}
 	val, success = ctx.Store[key]
 	return
 }
 
 // GetState fetches BaseContext.State.
 func (ctx *BaseContext) GetState() map[string]any {
+	defer ctx.rlock()()
 	return ctx.State
 }
 
 // SetState assigns a value to BaseContext.State.
 func (ctx *BaseContext) SetState(state map[string]any) {
+	unlock := ctx.lock()
+	old := ctx.State
 	ctx.State = state
+	unlock()
+	if ctx.changeLog != nil && !reflect.DeepEqual(old, state) {
+		ctx.changeLog.append(ctx.Clock(), "setState", "", old, state)
+	}
 }
 
+// StepFunc is the signature of a workflow step: it receives the
+// context.Context of the current run, the incoming event and the workflow
+// context, and returns the event that triggers the following step.
+// Long-running steps should honor cancellation of the context.Context.
+type StepFunc func(context.Context, *BaseEvent, *BaseContext) *BaseEvent
+
 // GenericWorkflow is an interface providing a generic implementation of
 // an event-driven workflow. Every struct representing a workflow should
 // implement the GenericWorkflow interface.
 //
-// A workflow should be composed of steps, functions that take a GenericEvent and GenericContext as arguments: these steps should
+// A workflow should be composed of steps, functions that take a context.Context, a GenericEvent and a GenericContext as arguments: these steps should
 // be in some way associated with strings representing their names.
 type GenericWorkflow interface {
-	// TakeStep allows separate execution single steps. It executes a step by selecting it with its name and passing a GenericEvent and a GenericContext to it.
-	TakeStep(string, *GenericEvent, *GenericContext) *BaseEvent
+	// TakeStep allows separate execution single steps. It executes a step by selecting it with its name and passing a context.Context, a GenericEvent and a GenericContext to it.
+	TakeStep(context.Context, string, *GenericEvent, *GenericContext) *BaseEvent
+
+	// TakeStepE works like TakeStep, but returns an error instead of an output event when the step cannot be executed.
+	TakeStepE(context.Context, string, *GenericEvent, *GenericContext) (*BaseEvent, error)
 
 	// Validate ensures that the structure of the workflow is correct
 	Validate() bool
 
-	// Run runs the workflow until completion. It takes an input event and an
-	//  initial context, as well as three callback function, respectively
+	// Run runs the workflow until completion or until the context.Context
+	//  is cancelled. It takes a context.Context, an input event and an
+	//  initial context, as well as four callback function, respectively
 	//  for when an event starts being processed, for when a new event is
-	//  emitted and for the workflow output
-	Run(*GenericEvent, *GenericContext, func(*GenericEvent), func(*GenericEvent), func(any))
+	//  emitted, for the workflow output and for the errors of the run
+	Run(context.Context, *GenericEvent, *GenericContext, func(*GenericEvent), func(*GenericEvent), func(any), func(string, error))
 
 	// Output runs at the end of the workflow and returns the actual
 	//  workflow output.
 	Output(*GenericEvent, *GenericContext) any
 }
 
+// StepTracer is implemented by tracing integrations that observe the
+// execution of every step of a run, e.g. to create a span per step.
+type StepTracer interface {
+	// StartStep is called before a step is executed, with the name of the step and its 1-based index within the run. It returns the context.Context passed to the step, and a function that is called once the step returns, with the emitted event and with the error that prevented the step from being executed, or the *PanicError of a recovered panic.
+	StartStep(ctx context.Context, stepName string, stepIndex int) (context.Context, func(*BaseEvent, error))
+}
+
+// Middleware wraps the execution of a step, adding behavior before and
+// after it (e.g. logging, authorization checks or metrics) by calling the
+// next StepFunc.
+type Middleware func(next StepFunc) StepFunc
+
+// WorkflowOption configures a BaseWorkflow when it is created with
+// NewBaseWorkflow.
+type WorkflowOption func(*BaseWorkflow)
+
+// DefaultMaxSteps is the step limit assigned to workflows created with
+// NewBaseWorkflow.
+const DefaultMaxSteps = 1000
+
 // BaseWorkflow offers a base implementation of GenericWorkflow.
+//
+// MaxSteps caps the number of steps executed within a single Run, guarding
+// against cycles in the step routing: a value of 0 means unlimited.
+// MaxDuration likewise caps the wall time of a run, measured with the Clock
+// of its context and checked between steps, so that a slow step is not
+// interrupted: a value of 0 means unlimited.
+//
+// Transitions optionally declares, for each step, the names of the steps it
+// can route to. Since NextStep is decided at runtime, these declarations are
+// not enforced while running, but they allow Validate and UnreachableSteps
+// to statically check the structure of the workflow, and exporters such as
+// ToMermaid to render it.
+//
+// OnStepTiming, when set, is called after every executed step with the name
+// of the step and the wall-clock time it took, even if the step panics.
+//
+// ErrorStep is the name of the step that a panicking step routes to: when it
+// is empty, the workflow routes to 'end' instead.
+//
+// Tracer, when set, observes every step executed by Run.
+//
+// ReservedSteps lists names that steps cannot be registered with, in
+// addition to TerminalStep, which is always reserved.
+//
+// DefaultNextStep is where Run routes events with an empty NextStep, e.g.
+// returned by a step that forgot to set it: when it is empty, such events
+// route to 'end'.
+//
+// Logger, when set, receives a record for every step executed by Run: at
+// debug level when the step starts, at info level when it emits an event
+// and at error level when it cannot be executed.
+//
+// OnRunEnd, when set, is called once a run ends, with its output when it
+// reaches 'end' or with the error that stopped it; it is not called for
+// suspended runs. Runs started with RunWithEventBus call it once no events
+// are left, with their last output.
+//
+// OutputKey is the key of the Data of the final event that Output reads,
+// "output" when it is empty. TerminalStep is the name of the step that ends
+// a run, 'end' when it is empty: it replaces 'end' wherever the workflow
+// routes, validates or renders its steps. The helpers of this package that
+// build final events, e.g. RequireKeys, still route to 'end' and write
+// "output", so workflows changing these names should route such events
+// explicitly. OutputKeys, when set, makes Output gather several named
+// results instead, see Output.
+//
+// StepResolver, when set, is asked for the function to execute every time
+// a step is taken, with the step name and the workflow context, e.g. to
+// pick a cheaper model depending on a flag of the context: when it returns
+// nil, the step is looked up in Steps.
+//
+// Stats, when set, counts the visits of every step across all the runs of
+// the workflow, see HeatMap.
 type BaseWorkflow struct {
-	FirstStep string
-	Context   *BaseContext
-	Steps     map[string]func(*BaseEvent, *BaseContext) *BaseEvent
+	FirstStep       string
+	Context         *BaseContext
+	Steps           map[string]StepFunc
+	MaxSteps        int
+	Transitions     map[string][]string
+	OnStepTiming    func(stepName string, d time.Duration)
+	ErrorStep       string
+	Tracer          StepTracer
+	Logger          *slog.Logger
+	ReservedSteps   []string
+	DefaultNextStep string
+	OnRunEnd        func(output any, err error)
+	OutputKey       string
+	TerminalStep    string
+	StepResolver    func(stepName string, wfCtx *BaseContext) StepFunc
+	OutputKeys      []string
+	Stats           *StepStats
+	MaxDuration     time.Duration
+
+	// middlewares holds the middlewares registered with Use.
+	middlewares []Middleware
+	// stepOrder holds the names of the steps in registration order, see
+	// StepNames.
+	stepOrder []string
+	// preprocessors holds the functions registered with AddPreprocessor.
+	preprocessors []func(*BaseEvent) *BaseEvent
+	// enterHooks and exitHooks hold the hooks registered with OnEnter and
+	// OnExit, by step name.
+	enterHooks map[string][]StepHook
+	exitHooks  map[string][]StepHook
+	// compensations holds the compensations registered with
+	// RegisterCompensation, by step name.
+	compensations map[string]StepHook
+	// switches holds the steps disabled with DisableStep.
+	switches *stepSwitches
 }
 
 // Validate checks that the steps in the workflow are not named with 'end',
-// a keyword reserved for the name of the output step.
+// a keyword reserved for the name of the output step, nor with any of the
+// ReservedSteps or with an empty name, that FirstStep is a registered step,
+// that DefaultNextStep and ErrorStep, when set, are registered steps or
+// 'end', and that every declared transition points either to a registered
+// step or to 'end'.
+//
+// Validate also rejects the cycles of declared transitions that can never
+// reach 'end', which would loop until MaxSteps: a cycle is allowed when at
+// least one of its steps transitions toward 'end', directly or through
+// other steps. Steps that declare no transitions are assumed to be able to
+// reach 'end'.
+//
+// Errors about a specific step are a *StepError wrapping
+// ErrInvalidFirstStep, ErrStepNotFound or ErrReservedKeyword.
 func (wf *BaseWorkflow) Validate() (bool, error) {
-	for k := range wf.Steps {
-		if k == "end" {
-			return false, errors.New("`end` is a reserved keyword, you cannot use it as a name for your steps")
+	for _, k := range wf.StepNames() {
+		if err := wf.checkStepName(k); err != nil {
+			return false, err
 		}
 	}
+	if _, ok := wf.Steps[wf.FirstStep]; !ok {
+		return false, stepError(wf.FirstStep, ErrInvalidFirstStep, "the first step %s is not a registered step", wf.FirstStep)
+	}
+	if _, ok := wf.Steps[wf.DefaultNextStep]; !ok && wf.DefaultNextStep != "" && wf.DefaultNextStep != wf.terminal() {
+		return false, stepError(wf.DefaultNextStep, ErrStepNotFound, "the default next step %s is not a registered step", wf.DefaultNextStep)
+	}
+	if _, ok := wf.Steps[wf.ErrorStep]; !ok && wf.ErrorStep != "" && wf.ErrorStep != wf.terminal() {
+		return false, stepError(wf.ErrorStep, ErrStepNotFound, "the error step %s is not a registered step", wf.ErrorStep)
+	}
+	for _, from := range sortedKeys(wf.Transitions) {
+		if _, ok := wf.Steps[from]; !ok {
+			return false, stepError(from, ErrStepNotFound, "transitions are declared for %s, which is not a registered step", from)
+		}
+		for _, to := range wf.Transitions[from] {
+			if to == "" {
+				return false, fmt.Errorf("step %s declares a transition to an empty step name, use DefaultNextStep instead", from)
+			}
+			if _, ok := wf.Steps[to]; !ok && to != wf.terminal() {
+				return false, stepError(to, ErrStepNotFound, "step %s declares a transition to %s, which is not a registered step", from, to)
+			}
+		}
+	}
+	if cycle := wf.closedCycle(); cycle != nil {
+		return false, fmt.Errorf("declared transitions form a cycle with no way to '%s': %s", wf.terminal(), strings.Join(cycle, " -> "))
+	}
 	return true, nil
 }
 
+// closedCycle returns a cycle of declared transitions that cannot reach
+// 'end', listing its steps from the first one back to it, or nil if there
+// is none.
+func (wf *BaseWorkflow) closedCycle() []string {
+	canExit := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for from, targets := range wf.Transitions {
+			if canExit[from] {
+				continue
+			}
+			for _, to := range targets {
+				if to == wf.terminal() || len(wf.Transitions[to]) == 0 || canExit[to] {
+					canExit[from] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	for _, start := range sortedKeys(wf.Transitions) {
+		if canExit[start] {
+			continue
+		}
+		path := []string{}
+		for step := start; ; {
+			if i := slices.Index(path, step); i >= 0 {
+				return append(path[i:], step)
+			}
+			path = append(path, step)
+			targets := slices.Clone(wf.Transitions[step])
+			if len(targets) == 0 {
+				break
+			}
+			slices.Sort(targets)
+			step = targets[0]
+		}
+	}
+	return nil
+}
+
+// checkStepName returns an error if a name cannot be used for a step.
+func (wf *BaseWorkflow) checkStepName(name string) error {
+	if name == "" {
+		return errors.New("steps cannot have an empty name")
+	}
+	if name == wf.terminal() || slices.Contains(wf.ReservedSteps, name) {
+		return stepError(name, ErrReservedKeyword, "`%s` is a reserved keyword, you cannot use it as a name for your steps", name)
+	}
+	return nil
+}
+
+// StepHook is a function attached to a specific step with OnEnter or
+// OnExit.
+type StepHook func(*BaseEvent, *BaseContext)
+
+// OnEnter registers a hook invoked during a run every time the step named
+// stepName is about to be executed, with the event it receives. Hooks of
+// the same step run in registration order.
+func (wf *BaseWorkflow) OnEnter(stepName string, fn StepHook) {
+	if wf.enterHooks == nil {
+		wf.enterHooks = map[string][]StepHook{}
+	}
+	wf.enterHooks[stepName] = append(wf.enterHooks[stepName], fn)
+}
+
+// OnExit registers a hook invoked during a run every time the step named
+// stepName returns, with the event it emitted. Hooks of the same step run
+// in registration order.
+func (wf *BaseWorkflow) OnExit(stepName string, fn StepHook) {
+	if wf.exitHooks == nil {
+		wf.exitHooks = map[string][]StepHook{}
+	}
+	wf.exitHooks[stepName] = append(wf.exitHooks[stepName], fn)
+}
+
+// Use registers a middleware that wraps every step executed by TakeStep and
+// Run. Middlewares are applied in registration order, the first registered
+// being the outermost.
+func (wf *BaseWorkflow) Use(mw Middleware) {
+	wf.middlewares = append(wf.middlewares, mw)
+}
+
+// AddPreprocessor registers a function that transforms every event before
+// it is passed to a step by TakeStep and Run, e.g. to normalize its Data.
+// Preprocessors are applied in registration order, each receiving the event
+// returned by the previous one; the first receives a clone of the event, so
+// that they can modify it in place.
+func (wf *BaseWorkflow) AddPreprocessor(fn func(*BaseEvent) *BaseEvent) {
+	wf.preprocessors = append(wf.preprocessors, fn)
+}
+
+// AddStep registers a step under the given name, returning an error if the
+// name is empty, 'end' or one of the ReservedSteps, or if a step with the
+// same name is already registered.
+func (wf *BaseWorkflow) AddStep(name string, fn StepFunc) error {
+	if err := wf.checkStepName(name); err != nil {
+		return err
+	}
+	if _, ok := wf.Steps[name]; ok {
+		return fmt.Errorf("step %s is already registered", name)
+	}
+	if wf.Steps == nil {
+		wf.Steps = map[string]StepFunc{}
+	}
+	wf.Steps[name] = fn
+	wf.stepOrder = append(wf.stepOrder, name)
+	return nil
+}
+
+// RemoveStep unregisters the step with the given name, together with the
+// transitions it declares. Removing a step that is not registered is a no-op.
+func (wf *BaseWorkflow) RemoveStep(name string) {
+	delete(wf.Steps, name)
+	delete(wf.Transitions, name)
+	if i := slices.Index(wf.stepOrder, name); i >= 0 {
+		wf.stepOrder = slices.Delete(wf.stepOrder, i, i+1)
+	}
+}
+
+// UnreachableSteps returns the sorted names of the registered steps that
+// cannot be reached from FirstStep by following the declared Transitions.
+// It returns nil when no transitions are declared, since reachability
+// cannot be determined in that case.
+func (wf *BaseWorkflow) UnreachableSteps() []string {
+	if len(wf.Transitions) == 0 {
+		return nil
+	}
+	visited := map[string]bool{}
+	queue := []string{wf.FirstStep}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		queue = append(queue, wf.Transitions[current]...)
+	}
+	unreachable := []string{}
+	for _, name := range sortedKeys(wf.Steps) {
+		if !visited[name] {
+			unreachable = append(unreachable, name)
+		}
+	}
+	return unreachable
+}
+
+// StepNames returns the names of the registered steps in registration
+// order: the steps passed to NewBaseWorkflow come first, sorted
+// alphabetically, followed by the ones registered with AddStep. Steps
+// added to the Steps map directly are listed last, sorted alphabetically.
+func (wf *BaseWorkflow) StepNames() []string {
+	names := make([]string, 0, len(wf.Steps))
+	listed := map[string]bool{}
+	for _, name := range wf.stepOrder {
+		if _, ok := wf.Steps[name]; ok && !listed[name] {
+			names = append(names, name)
+			listed[name] = true
+		}
+	}
+	for _, name := range sortedKeys(wf.Steps) {
+		if !listed[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// HasStep reports whether a step is registered under name.
+func (wf *BaseWorkflow) HasStep(name string) bool {
+	_, ok := wf.Steps[name]
+	return ok
+}
+
+// ResetContext clears the Context of the workflow between runs, replacing
+// its Store and State with empty maps and dropping its history, TTLs and
+// snapshots; its Clock is kept. A nil Context is replaced by an empty one.
+func (wf *BaseWorkflow) ResetContext() {
+	if wf.Context == nil {
+		wf.Context = NewBaseContext(map[string]any{}, map[string]any{})
+		return
+	}
+	defer wf.Context.lock()()
+	wf.Context.Store = map[string]any{}
+	wf.Context.State = map[string]any{}
+	wf.Context.expirations = nil
+	wf.Context.history = nil
+	wf.Context.snapshots = nil
+	wf.Context.timeline = nil
+}
+
+// ResetContextState works like ResetContext, but only replaces the State
+// of the Context with an empty map, keeping its Store.
+func (wf *BaseWorkflow) ResetContextState() {
+	if wf.Context == nil {
+		wf.Context = NewBaseContext(map[string]any{}, map[string]any{})
+		return
+	}
+	defer wf.Context.lock()()
+	wf.Context.State = map[string]any{}
+}
+
 // TakeStep allows separate execution single steps by calling
-// them with their name.
-func (wf *BaseWorkflow) TakeStep(stepName string, ev *BaseEvent, ctx *BaseContext) *BaseEvent {
+// them with their name. If the step does not exist, it returns an event
+// routing to 'end' whose output describes the error.
+func (wf *BaseWorkflow) TakeStep(ctx context.Context, stepName string, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+	event, err := wf.TakeStepE(ctx, stepName, ev, wfCtx)
+	if err != nil {
+		var data map[string]string = map[string]string{
+			wf.outputKey(): fmt.Sprintf("There was an error while executing step %s: the step does not exist", stepName),
+		}
+		return NewBaseEvent(wf.terminal(), data)
+	}
+	return event
+}
+
+// TakeStepE allows separate execution single steps by calling
+// them with their name, returning an error wrapping ErrStepNotFound if the
+// step does not exist.
+// An empty name selects DefaultNextStep, when it is set.
+//
+// If the step panics, the panic is recovered and turned into an event
+// routing to ErrorStep (or to 'end', with the error as output), that carries
+// the recovered value under the "panic" key and an error message under
+// ErrorKey.
+func (wf *BaseWorkflow) TakeStepE(ctx context.Context, stepName string, ev *BaseEvent, wfCtx *BaseContext) (*BaseEvent, error) {
+	event, err := wf.takeStep(ctx, stepName, ev, wfCtx)
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return event, nil
+	}
+	return event, err
+}
+
+// PanicError reports a panic recovered while executing a step.
+type PanicError struct {
+	Step  string
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("step %s panicked: %v", e.Step, e.Value)
+}
+
+// takeStep works like TakeStepE, but returns a *PanicError along with the
+// event built by panicEvent when the step panics.
+func (wf *BaseWorkflow) takeStep(ctx context.Context, stepName string, ev *BaseEvent, wfCtx *BaseContext) (event *BaseEvent, err error) {
+	if stepName == "" && wf.DefaultNextStep != "" {
+		stepName = wf.DefaultNextStep
+	}
 	step, ok := wf.Steps[stepName]
+	if wf.StepResolver != nil {
+		if resolved := wf.StepResolver(stepName, wfCtx); resolved != nil {
+			step, ok = resolved, true
+		}
+	}
 	if !ok {
-		var data map[string]string = map[string]string{
-			"output": fmt.Sprintf("There was an error while executing step %s: the step does not exist", stepName),
+		return nil, stepError(stepName, ErrStepNotFound, "step %s does not exist", stepName)
+	}
+	if target, disabled := wf.skipTarget(stepName); disabled {
+		return skipEvent(ev, target), nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			event = wf.panicEvent(stepName, r)
+			err = &PanicError{Step: stepName, Value: r}
 		}
-		return NewBaseEvent("end", data)
-	} else {
-		return step(ev, ctx)
+	}()
+	if wf.OnStepTiming != nil {
+		start := time.Now()
+		defer func() {
+			wf.OnStepTiming(stepName, time.Since(start))
+		}()
 	}
+	if len(wf.preprocessors) > 0 {
+		ev = ev.Clone()
+		for _, fn := range wf.preprocessors {
+			ev = fn(ev)
+		}
+	}
+	for i := len(wf.middlewares) - 1; i >= 0; i-- {
+		step = wf.middlewares[i](step)
+	}
+	return step(ctx, ev, wfCtx), nil
+}
+
+// Run runs the workflow through completion.
+//
+// The names of the executed steps, including 'end' when it is reached, are
+// recorded in the State of the context, see BaseContext.ExecutionTrace, and
+// the processed events in its history, see BaseContext.History. Every run
+// is given a unique identifier, see BaseContext.RunID, which callbacks can
+// read from the context and which is attached to the records of Logger.
+//
+// The context.Context is checked before every step: if it is cancelled
+// or its deadline expires, Run stops and passes the name of the step and the
+// context error to onErrorCallBack instead of producing an output. The same
+// happens, with an error reporting the limit, when the workflow executes
+// more than MaxSteps steps or runs for longer than MaxDuration, and with
+// the error returned by TakeStepE when a step does not exist. When a step
+// panics, the *PanicError is passed to onErrorCallBack and the run goes on
+// with the event built as described in TakeStepE. If onErrorCallBack is
+// nil, the errors that stop the run are passed to onOutputCallBack instead,
+// and panics are not reported.
+//
+// A step returning an event built by Suspend stops the run, which outputs a
+// Suspended value, see SuspendableWorkflow, while a step returning an event
+// built by AbortEvent stops it with an error, as described above.
+//
+// A step returning an event with NextSteps fans the run out: NextStep is
+// ignored and every step of NextSteps is run concurrently, in its own
+// branch, from a copy of the event. The branches share the context and go
+// on independently, each counting its steps towards MaxSteps from the
+// number executed before the fan-out, until it reaches 'end' or stops; a
+// branch can fan out in turn. The run ends when every branch has ended: the
+// output is then a []any holding the output of each branch, in the order
+// of NextSteps, or the error that stopped it, which is also passed to
+// onErrorCallBack. If any branch failed, the compensations of the run are
+// invoked once every branch has ended, and OnRunEnd receives the errors of
+// the branches joined with errors.Join, which RunSync returns. The
+// callbacks are invoked from the goroutines of the branches, but never
+// concurrently: a callback of one branch waits for those of the others.
+//
+// Run is kept for compatibility: RunWith lets callers set only the
+// callbacks they need.
+func (wf *BaseWorkflow) Run(ctx context.Context, inputEvent *BaseEvent, wfCtx *BaseContext, onEventStartCallBack func(*BaseEvent), onEventEndCallBack func(*BaseEvent), onOutputCallBack func(any), onErrorCallBack func(stepName string, err error)) {
+	wf.RunWith(ctx, inputEvent, wfCtx, WithOnStart(onEventStartCallBack), WithOnEnd(onEventEndCallBack), WithOnOutput(onOutputCallBack), WithOnError(onErrorCallBack))
+}
+
+// stepResolver produces the event emitted by a step within a run. Run uses
+// runStep, which executes the registered step, while DryRun looks the event
+// up among its stubs. An error returned along with an event is reported
+// without stopping the run.
+type stepResolver func(ctx context.Context, stepName string, stepIndex int, ev *BaseEvent, wfCtx *BaseContext) (*BaseEvent, error)
+
+// start runs the workflow from FirstStep, resolving every step with resolve.
+func (wf *BaseWorkflow) start(ctx context.Context, inputEvent *BaseEvent, wfCtx *BaseContext, resolve stepResolver, opts *RunOptions) {
+	wf.startTimer(opts, wfCtx)
+	opts.Pause.wait(ctx)
+	if err := wf.checkStep(ctx, opts, wf.FirstStep, 0); err != nil {
+		wf.fail(opts, wf.FirstStep, err)
+		return
+	}
+	wfCtx.beginRun(inputEvent, opts.RunID)
+	event, err := resolve(ctx, wf.FirstStep, 1, inputEvent, wfCtx)
+	if !wf.report(opts, wf.FirstStep, event, err) {
+		return
+	}
+	wfCtx.appendTrace(wf.FirstStep)
+	wf.run(ctx, event, wfCtx, 1, resolve, opts)
 }
 
-// Run runs the workflow through completion
-func (wf *BaseWorkflow) Run(inputEvent *BaseEvent, context *BaseContext, onEventStartCallBack func(*BaseEvent), onEventEndCallBack func(*BaseEvent), onOutputCallBack func(any)) {
-	event := wf.TakeStep(wf.FirstStep, inputEvent, context)
+// DryRun walks the routing of the workflow without executing any step:
+// the event emitted by each step is taken from stub, keyed by step name,
+// instead of invoking the step itself. It returns the names of the steps
+// visited, including 'end' when it is reached, in the same form as
+// BaseContext.ExecutionTrace.
+//
+// The walk stops at the first step without a stub, or that is not
+// registered, and when MaxSteps is exceeded. The context is cloned, so
+// that ctx is left untouched; a nil ctx is replaced by an empty one.
+func (wf *BaseWorkflow) DryRun(ev *BaseEvent, ctx *BaseContext, stub map[string]*BaseEvent) []string {
+	if ctx == nil {
+		ctx = NewBaseContext(map[string]any{}, map[string]any{})
+	}
+	wfCtx := ctx.Clone()
+	resolve := func(_ context.Context, stepName string, _ int, _ *BaseEvent, _ *BaseContext) (*BaseEvent, error) {
+		if _, ok := wf.Steps[stepName]; !ok {
+			return nil, stepError(stepName, ErrStepNotFound, "step %s does not exist", stepName)
+		}
+		event, ok := stub[stepName]
+		if !ok || event == nil {
+			return nil, fmt.Errorf("no stub for step %s", stepName)
+		}
+		return event.Clone(), nil
+	}
+	opts := newRunOptions()
+	opts.dryRun = true
+	wf.start(context.Background(), ev, wfCtx, resolve, opts)
+	return wfCtx.ExecutionTrace()
+}
+
+// RunSync runs the workflow through completion like Run, without callbacks,
+// returning its output directly. If the run stops because of a missing step,
+// cancellation or MaxSteps, or if a branch of a run that fans out fails, the
+// output is nil and the error is returned.
+func (wf *BaseWorkflow) RunSync(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) (any, error) {
+	var res runResult
+	wf.RunWith(ctx, ev, wfCtx, res.option())
+	return res.get()
+}
+
+// panicEvent builds the event emitted when a step panics.
+func (wf *BaseWorkflow) panicEvent(stepName string, recovered any) *BaseEvent {
+	message := (&PanicError{Step: stepName, Value: recovered}).Error()
+	data := map[string]string{
+		ErrorKey: message,
+		"panic":  fmt.Sprint(recovered),
+	}
+	if wf.ErrorStep == "" {
+		data[wf.outputKey()] = message
+		return NewBaseEvent(wf.terminal(), data)
+	}
+	return NewBaseEvent(wf.ErrorStep, data)
+}
+
+// RunFrom resumes the workflow from an event that was already emitted, e.g.
+// one restored with Resume, running the step named by its NextStep instead
+// of FirstStep. It behaves like Run in every other respect.
+func (wf *BaseWorkflow) RunFrom(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext, onEventStartCallBack func(*BaseEvent), onEventEndCallBack func(*BaseEvent), onOutputCallBack func(any), onErrorCallBack func(stepName string, err error)) {
+	wf.runFrom(ctx, ev, wfCtx, newRunOptions(WithOnStart(onEventStartCallBack), WithOnEnd(onEventEndCallBack), WithOnOutput(onOutputCallBack), WithOnError(onErrorCallBack)))
+}
+
+// runFrom runs the workflow from an already emitted event, like RunFrom,
+// with the given options.
+func (wf *BaseWorkflow) runFrom(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext, opts *RunOptions) {
+	wf.run(ctx, ev, wfCtx, 0, wf.newSaga(wfCtx, opts).resolve, opts)
+}
+
+// run processes events until the workflow reaches 'end' or stops, starting
+// from an already emitted event and the number of steps executed so far, and
+// resolving every step with resolve.
+func (wf *BaseWorkflow) run(ctx context.Context, event *BaseEvent, wfCtx *BaseContext, stepCount int, resolve stepResolver, opts *RunOptions) {
+	wf.startTimer(opts, wfCtx)
+	var err error
 	for {
-		onEventStartCallBack(event)
-		if event.NextStep == "end" {
-			output := wf.Output(event, context)
-			onOutputCallBack(output)
+		if len(event.NextSteps) > 0 {
+			wfCtx.AppendEvent(event)
+			opts.OnStart(event)
+			wf.fanOut(ctx, event, wfCtx, stepCount, resolve, opts)
+			break
+		}
+		wf.routeEmpty(event)
+		wfCtx.AppendEvent(event)
+		opts.OnStart(event)
+		if event.NextStep == SuspendStep {
+			suspended := wfCtx.suspend(event)
+			opts.OnOutput(suspended)
+			if opts.onResult != nil {
+				opts.onResult(suspended, nil)
+			}
+			break
+		}
+		if event.NextStep == AbortStep {
+			wf.fail(opts, event.SourceStep, abortError(event))
 			break
 		}
-		event = wf.TakeStep(event.NextStep, event, context)
-		onEventEndCallBack(event)
+		if event.NextStep == wf.terminal() {
+			wfCtx.appendTrace(event.NextStep)
+			output := wf.Output(event, wfCtx)
+			opts.OnOutput(output)
+			wf.endRun(opts, output, nil)
+			break
+		}
+		opts.Pause.wait(ctx)
+		if err := wf.checkStep(ctx, opts, event.NextStep, stepCount); err != nil {
+			wf.fail(opts, event.NextStep, err)
+			break
+		}
+		stepName := event.NextStep
+		event, err = resolve(ctx, stepName, stepCount+1, event, wfCtx)
+		if !wf.report(opts, stepName, event, err) {
+			break
+		}
+		wfCtx.appendTrace(stepName)
+		stepCount++
+		opts.OnEnd(event)
+	}
+}
+
+// routeEmpty routes an event with an empty NextStep to DefaultNextStep, or
+// to 'end' when DefaultNextStep is not set.
+func (wf *BaseWorkflow) routeEmpty(ev *BaseEvent) {
+	if ev.NextStep != "" {
+		return
+	}
+	ev.NextStep = wf.DefaultNextStep
+	if ev.NextStep == "" {
+		ev.NextStep = wf.terminal()
+	}
+}
+
+// checkStep returns the error that prevents a run from executing the given
+// step after stepCount steps, if any.
+func (wf *BaseWorkflow) checkStep(ctx context.Context, opts *RunOptions, stepName string, stepCount int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("workflow stopped before step %s: %w", stepName, err)
+	}
+	if opts.stopping() {
+		return stepError(stepName, ErrRunnerShutdown, "workflow stopped before step %s: the runner is shutting down", stepName)
+	}
+	if !opts.deadline.IsZero() && !opts.clock.Now().Before(opts.deadline) {
+		return stepError(stepName, ErrMaxDurationExceeded, "workflow stopped before step %s: exceeded the maximum duration of %s", stepName, wf.MaxDuration)
+	}
+	if wf.MaxSteps > 0 && stepCount >= wf.MaxSteps {
+		return stepError(stepName, ErrStepLimitExceeded, "workflow stopped before step %s: exceeded the maximum of %d steps", stepName, wf.MaxSteps)
+	}
+	return nil
+}
+
+// startTimer sets the deadline of a run from MaxDuration, unless it is
+// already set.
+func (wf *BaseWorkflow) startTimer(opts *RunOptions, wfCtx *BaseContext) {
+	if wf.MaxDuration <= 0 || !opts.deadline.IsZero() {
+		return
+	}
+	opts.clock = wfCtx.Clock()
+	opts.deadline = opts.clock.Now().Add(wf.MaxDuration)
+}
+
+// WithLogger is a WorkflowOption that sets the Logger of the workflow.
+func WithLogger(logger *slog.Logger) WorkflowOption {
+	return func(wf *BaseWorkflow) {
+		wf.Logger = logger
+	}
+}
+
+// WithStepResolver is a WorkflowOption that sets the StepResolver of the
+// workflow.
+func WithStepResolver(resolver func(stepName string, wfCtx *BaseContext) StepFunc) WorkflowOption {
+	return func(wf *BaseWorkflow) {
+		wf.StepResolver = resolver
+	}
+}
+
+// runStep executes a step within a run, recording it as the CurrentStep of
+// the context, invoking its OnEnter and OnExit hooks and reporting it to
+// the Tracer and to the Logger if they are set.
+func (wf *BaseWorkflow) runStep(ctx context.Context, stepName string, stepIndex int, ev *BaseEvent, wfCtx *BaseContext) (*BaseEvent, error) {
+	if wf.Logger != nil {
+		wf.Logger.LogAttrs(ctx, slog.LevelDebug, "step started", slog.String("runID", wfCtx.RunID()), slog.String("step", stepName), slog.Int("stepIndex", stepIndex))
+	}
+	stepCtx, end := ctx, func(*BaseEvent, error) {}
+	if wf.Tracer != nil {
+		stepCtx, end = wf.Tracer.StartStep(ctx, stepName, stepIndex)
+	}
+	wfCtx.setCurrentStep(stepName, stepIndex)
+	_, skipped := wf.skipTarget(stepName)
+	registered := wf.HasStep(stepName) && !skipped
+	if registered {
+		for _, hook := range wf.enterHooks[stepName] {
+			hook(ev, wfCtx)
+		}
+	}
+	start := wfCtx.Clock().Now()
+	event, err := wf.takeStep(stepCtx, stepName, ev, wfCtx)
+	if !skipped && !errors.Is(err, ErrStepNotFound) {
+		wf.Stats.visit(stepName)
+		wfCtx.appendSpan(StepSpan{StepName: stepName, Start: start, End: wfCtx.Clock().Now()})
+	}
+	stamp(event, stepName, wfCtx)
+	if registered {
+		for _, hook := range wf.exitHooks[stepName] {
+			hook(event, wfCtx)
+		}
 	}
+	end(event, err)
+	if wf.Logger != nil {
+		if err != nil {
+			wf.Logger.LogAttrs(ctx, slog.LevelError, "step failed", slog.String("runID", wfCtx.RunID()), slog.String("step", stepName), slog.Int("stepIndex", stepIndex), slog.String("error", err.Error()))
+		} else if event != nil {
+			wf.Logger.LogAttrs(ctx, slog.LevelInfo, "event emitted", slog.String("runID", wfCtx.RunID()), slog.String("step", stepName), slog.Int("stepIndex", stepIndex), slog.String("nextStep", event.NextStep))
+		}
+	}
+	return event, err
 }
 
-// Output produces the output of the workflow.
+// stamp sets the SourceStep of an event emitted by stepName and, unless the
+// step already set it, its CreatedAt, using the Clock of the context.
+func stamp(ev *BaseEvent, stepName string, wfCtx *BaseContext) {
+	if ev == nil {
+		return
+	}
+	ev.SourceStep = stepName
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = wfCtx.Clock().Now()
+	}
+}
+
+// terminal returns the name of the step that ends a run.
+func (wf *BaseWorkflow) terminal() string {
+	if wf.TerminalStep == "" {
+		return "end"
+	}
+	return wf.TerminalStep
+}
+
+// outputKey returns the key of the Data of the final event holding the
+// output of the workflow.
+func (wf *BaseWorkflow) outputKey() string {
+	if wf.OutputKey == "" {
+		return "output"
+	}
+	return wf.OutputKey
+}
+
+// Output produces the output of the workflow, read from the Data of the
+// event routing to TerminalStep under OutputKey. When OutputKeys is set,
+// the output is instead a map[string]any holding the values of the event
+// under each of OutputKeys, leaving out the keys it does not hold.
 func (wf *BaseWorkflow) Output(ev *BaseEvent, ctx *BaseContext) any {
-	if ev.NextStep == "end" {
-		output, ok := ev.Get("output")
+	if ev.NextStep == wf.terminal() {
+		if len(wf.OutputKeys) > 0 {
+			outputs := map[string]any{}
+			for _, key := range wf.OutputKeys {
+				if val, ok := ev.Get(key); ok {
+					outputs[key] = val
+				}
+			}
+			return outputs
+		}
+		output, ok := ev.Get(wf.outputKey())
 		if ok {
 			return output
 		}
@@ -180,10 +1116,34 @@ func (wf *BaseWorkflow) Output(ev *BaseEvent, ctx *BaseContext) any {
 
 // NewBaseWorkflow creates a new BaseWorkflow instance starting from the definition
 // of the first step, a context instance and a map that represents steps.
-func NewBaseWorkflow(firstStep string, ctx *BaseContext, steps map[string]func(*BaseEvent, *BaseContext) *BaseEvent) *BaseWorkflow {
-	return &BaseWorkflow{
-		FirstStep: firstStep,
-		Context:   ctx,
-		Steps:     steps,
+// The returned workflow has MaxSteps set to DefaultMaxSteps, ReservedSteps
+// set to 'end', OutputKey to "output", TerminalStep to 'end' and empty
+// Stats, and is then configured by the given options, in order.
+func NewBaseWorkflow(firstStep string, ctx *BaseContext, steps map[string]StepFunc, opts ...WorkflowOption) *BaseWorkflow {
+	wf := &BaseWorkflow{
+		FirstStep:     firstStep,
+		Context:       ctx,
+		Steps:         steps,
+		MaxSteps:      DefaultMaxSteps,
+		ReservedSteps: []string{"end"},
+		OutputKey:     "output",
+		TerminalStep:  "end",
+		Stats:         NewStepStats(),
+		stepOrder:     sortedKeys(steps),
+		switches:      &stepSwitches{},
+	}
+	for _, opt := range opts {
+		opt(wf)
+	}
+	return wf
+}
+
+// sortedKeys returns the keys of a map sorted in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	slices.Sort(keys)
+	return keys
 }
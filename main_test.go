@@ -1,9 +1,15 @@
 package workflowsgo
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"maps"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestEvent(t *testing.T) {
@@ -35,6 +41,41 @@ func TestEvent(t *testing.T) {
 	}
 }
 
+func TestTypedEvent(t *testing.T) {
+	embedding := []float64{0.1, 0.2, 0.3}
+	var event GenericEvent = NewTypedEvent("embed", map[string][]float64{"embedding": embedding})
+	val, ok := event.Get("embedding")
+	if !ok {
+		t.Fatal("Testing TypedEvent.Get: expected the key to be present")
+	}
+	if v, ok := val.([]float64); !ok || !slices.Equal(v, embedding) {
+		t.Errorf("Testing NewTypedEvent and TypedEvent.Get: want %v, got %v", embedding, val)
+	}
+	if _, ok := event.Get("missing"); ok {
+		t.Error("Testing TypedEvent.Get: expected a missing key not to be found")
+	}
+}
+
+func TestGetTyped(t *testing.T) {
+	typed := NewTypedEvent("count", map[string]any{"count": 3, "name": "three"})
+	if count, ok := GetTyped[int](typed, "count"); !ok || count != 3 {
+		t.Errorf("Testing GetTyped: want 3 and true, got %d and %v", count, ok)
+	}
+	if count, ok := GetTyped[int](typed, "name"); ok || count != 0 {
+		t.Errorf("Testing GetTyped with a mismatching type: want 0 and false, got %d and %v", count, ok)
+	}
+	if _, ok := GetTyped[int](typed, "missing"); ok {
+		t.Error("Testing GetTyped with a missing key: want false, got true")
+	}
+	base := NewBaseEvent("end", map[string]string{"count": "3"})
+	if count, ok := GetTyped[string](base, "count"); !ok || count != "3" {
+		t.Errorf("Testing GetTyped on a BaseEvent: want '3' and true, got %s and %v", count, ok)
+	}
+	if _, ok := GetTyped[int](base, "count"); ok {
+		t.Error("Testing GetTyped on a BaseEvent with a string-valued key: want false, got true")
+	}
+}
+
 type User struct {
 	name  string
 	email string
@@ -84,12 +125,59 @@ func TestContext(t *testing.T) {
 	}
 }
 
-func mockStep(ev *BaseEvent, ctx *BaseContext) *BaseEvent {
+func TestContextConcurrentAccess(t *testing.T) {
+	ctx := NewBaseContext(map[string]any{}, map[string]any{})
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx.StoreValue("counter", i)
+			if _, ok := ctx.GetValue("counter"); !ok {
+				t.Error("Testing BaseContext concurrent access: expected the key to be present")
+			}
+			ctx.SetState(map[string]any{"last": i})
+			_ = ctx.GetState()
+		}(i)
+	}
+	wg.Wait()
+	if val, ok := ctx.GetValue("counter"); !ok || val.(int) < 0 || val.(int) >= 100 {
+		t.Errorf("Testing BaseContext concurrent access: unexpected final value %v", val)
+	}
+}
+
+func TestContextTTL(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	ctx := NewBaseContext(map[string]any{}, map[string]any{}, WithClock(clock))
+	ctx.StoreValueWithTTL("apiToken", "secret", 5*time.Minute)
+	ctx.StoreValue("user", "John Doe")
+	if token, ok := ctx.GetValue("apiToken"); !ok || token != "secret" {
+		t.Errorf("Testing BaseContext.StoreValueWithTTL before expiry: want 'secret' and true, got %v and %v", token, ok)
+	}
+	clock.Advance(5 * time.Minute)
+	if token, ok := ctx.GetValue("apiToken"); ok || token != nil {
+		t.Errorf("Testing BaseContext.StoreValueWithTTL after expiry: want nil and false, got %v and %v", token, ok)
+	}
+	if _, ok := ctx.Store["apiToken"]; ok {
+		t.Error("Testing BaseContext.StoreValueWithTTL after expiry: expected the value to be evicted")
+	}
+	if user, ok := ctx.GetValue("user"); !ok || user != "John Doe" {
+		t.Errorf("Testing BaseContext.GetValue without TTL: want 'John Doe' and true, got %v and %v", user, ok)
+	}
+	ctx.StoreValueWithTTL("apiToken", "secret", time.Minute)
+	ctx.StoreValue("apiToken", "refreshed")
+	clock.Advance(time.Hour)
+	if token, ok := ctx.GetValue("apiToken"); !ok || token != "refreshed" {
+		t.Errorf("Testing BaseContext.StoreValue after StoreValueWithTTL: want 'refreshed' and true, got %v and %v", token, ok)
+	}
+}
+
+func mockStep(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
 	return NewBaseEvent("end", map[string]string{"output": "hello world"})
 }
 
 func TestWorkflow(t *testing.T) {
-	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]func(*BaseEvent, *BaseContext) *BaseEvent{"firstStep": mockStep})
+	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"firstStep": mockStep})
 	_, err := any(wf).(BaseWorkflow)
 	if err {
 		t.Error("Testing NewBaseWorkflow: NewBaseWorkflow does not return an instance of BaseWorkflow")
@@ -102,7 +190,7 @@ func TestWorkflow(t *testing.T) {
 	if !valid {
 		t.Error("Testing BaseWorkflow.Validate: BaseWorkflow is not valid, but it should be")
 	}
-	event := wf.TakeStep("firstStep", NewBaseEvent("mockEvent", map[string]string{"mock": "event"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	event := wf.TakeStep(context.Background(), "firstStep", NewBaseEvent("mockEvent", map[string]string{"mock": "event"}), NewBaseContext(map[string]any{}, map[string]any{}))
 	if val, _ := event.Get("output"); val != "hello world" {
 		t.Errorf("Testing BaseWorkflow.TakeStep: Expected 'hello world', gotten %s", val)
 	}
@@ -129,8 +217,667 @@ func TestWorkflow(t *testing.T) {
 		outputCallBacks = append(outputCallBacks, out)
 	}
 
-	wf.Run(NewBaseEvent("mockEvent", map[string]string{"mock": "event"}), NewBaseContext(map[string]any{}, map[string]any{}), startEventCallBack, endEventCallBack, outputCallBack)
+	wf.Run(context.Background(), NewBaseEvent("mockEvent", map[string]string{"mock": "event"}), NewBaseContext(map[string]any{}, map[string]any{}), startEventCallBack, endEventCallBack, outputCallBack, nil)
 	if !slices.Equal(startCallBacks, []string{"end"}) || !slices.Equal(outputCallBacks, []any{"hello world"}) || len(endCallBacks) != 0 {
 		t.Errorf("Testing for BaseWorkflow.Run: want %v, %v, %d\ngot %v, %v, %d", []string{"end"}, []string{"hello world"}, 0, startCallBacks, outputCallBacks, len(endCallBacks))
 	}
 }
+
+func loopingStep(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * time.Millisecond):
+	}
+	return NewBaseEvent("loop", map[string]string{})
+}
+
+func TestWorkflowCancellation(t *testing.T) {
+	wf := NewBaseWorkflow("loop", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"loop": loopingStep})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	outputs := []any{}
+	done := make(chan struct{})
+	go func() {
+		wf.Run(ctx, NewBaseEvent("loop", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+			outputs = append(outputs, out)
+		}, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Testing BaseWorkflow.Run with cancellation: Run did not return after the context deadline expired")
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("Testing BaseWorkflow.Run with cancellation: want 1 output, got %d", len(outputs))
+	}
+	if err, ok := outputs[0].(error); !ok || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Testing BaseWorkflow.Run with cancellation: want an error wrapping %v, got %v", context.DeadlineExceeded, outputs[0])
+	}
+}
+
+func TestWorkflowMaxSteps(t *testing.T) {
+	steps := map[string]StepFunc{
+		"a": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("b", map[string]string{})
+		},
+		"b": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("a", map[string]string{})
+		},
+	}
+	wf := NewBaseWorkflow("a", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	if wf.MaxSteps != DefaultMaxSteps {
+		t.Errorf("Testing NewBaseWorkflow: want MaxSteps %d, got %d", DefaultMaxSteps, wf.MaxSteps)
+	}
+	wf.MaxSteps = 10
+	executed := 0
+	outputs := []any{}
+	wf.Run(context.Background(), NewBaseEvent("a", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) { executed++ }, func(out any) {
+		outputs = append(outputs, out)
+	}, nil)
+	if executed+1 != 10 {
+		t.Errorf("Testing BaseWorkflow.Run with MaxSteps: want 10 executed steps, got %d", executed+1)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("Testing BaseWorkflow.Run with MaxSteps: want 1 output, got %d", len(outputs))
+	}
+	if err, ok := outputs[0].(error); !ok || !strings.Contains(err.Error(), "10") {
+		t.Errorf("Testing BaseWorkflow.Run with MaxSteps: want an error reporting the limit, got %v", outputs[0])
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	steps := map[string]StepFunc{"first": mockStep, "second": mockStep, "orphan": mockStep}
+	wf := NewBaseWorkflow("frist", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	valid, err := wf.Validate()
+	if valid || err == nil || !strings.Contains(err.Error(), "frist") {
+		t.Errorf("Testing BaseWorkflow.Validate with a missing first step: want an error naming %q, got %v", "frist", err)
+	}
+	wf.FirstStep = "first"
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate: BaseWorkflow is not valid, but it should be: %v", err)
+	}
+	if unreachable := wf.UnreachableSteps(); unreachable != nil {
+		t.Errorf("Testing BaseWorkflow.UnreachableSteps without transitions: want nil, got %v", unreachable)
+	}
+	wf.Transitions = map[string][]string{"first": {"second"}, "second": {"end"}}
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate with transitions: BaseWorkflow is not valid, but it should be: %v", err)
+	}
+	if unreachable := wf.UnreachableSteps(); !slices.Equal(unreachable, []string{"orphan"}) {
+		t.Errorf("Testing BaseWorkflow.UnreachableSteps: want %v, got %v", []string{"orphan"}, unreachable)
+	}
+	wf.Transitions["second"] = []string{"thrid"}
+	valid, err = wf.Validate()
+	if valid || err == nil || !strings.Contains(err.Error(), "thrid") {
+		t.Errorf("Testing BaseWorkflow.Validate with a dangling transition: want an error naming %q, got %v", "thrid", err)
+	}
+	wf.Transitions = map[string][]string{"first": {"second"}, "second": {"orphan"}, "orphan": {"second"}}
+	valid, err = wf.Validate()
+	if valid || err == nil || !strings.Contains(err.Error(), "second -> orphan -> second") {
+		t.Errorf("Testing BaseWorkflow.Validate with a closed cycle: want an error listing the cycle, got %v", err)
+	}
+	wf.Transitions["orphan"] = []string{"second", "end"}
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate with a cycle exiting to 'end': BaseWorkflow is not valid, but it should be: %v", err)
+	}
+	wf.Transitions = map[string][]string{"first": {"first", "second"}, "second": {}}
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate with a cycle exiting to a step declaring no transitions: BaseWorkflow is not valid, but it should be: %v", err)
+	}
+}
+
+func TestWorkflowTakeStepE(t *testing.T) {
+	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"firstStep": mockStep})
+	event, err := wf.TakeStepE(context.Background(), "firstStep", NewBaseEvent("firstStep", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil {
+		t.Errorf("Testing BaseWorkflow.TakeStepE: want no error, got %v", err)
+	}
+	if val, _ := event.Get("output"); val != "hello world" {
+		t.Errorf("Testing BaseWorkflow.TakeStepE: Expected 'hello world', gotten %s", val)
+	}
+	event, err = wf.TakeStepE(context.Background(), "typo", NewBaseEvent("typo", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err == nil || event != nil {
+		t.Errorf("Testing BaseWorkflow.TakeStepE with an unknown step: want a nil event and an error, got %v and %v", event, err)
+	}
+	event = wf.TakeStep(context.Background(), "typo", NewBaseEvent("typo", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if event.NextStep != "end" {
+		t.Errorf("Testing BaseWorkflow.TakeStep with an unknown step: Expected 'end', gotten %s", event.NextStep)
+	}
+}
+
+func TestWorkflowStepTiming(t *testing.T) {
+	steps := map[string]StepFunc{
+		"slow": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			time.Sleep(5 * time.Millisecond)
+			return NewBaseEvent("fast", map[string]string{})
+		},
+		"fast":  mockStep,
+		"panic": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent { panic("boom") },
+	}
+	wf := NewBaseWorkflow("slow", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	timings := map[string]time.Duration{}
+	names := []string{}
+	wf.OnStepTiming = func(stepName string, d time.Duration) {
+		names = append(names, stepName)
+		timings[stepName] = d
+	}
+	wf.Run(context.Background(), NewBaseEvent("slow", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(any) {}, nil)
+	if !slices.Equal(names, []string{"slow", "fast"}) {
+		t.Errorf("Testing BaseWorkflow.OnStepTiming: want %v, got %v", []string{"slow", "fast"}, names)
+	}
+	if timings["slow"] < 5*time.Millisecond {
+		t.Errorf("Testing BaseWorkflow.OnStepTiming: want at least 5ms for the slow step, got %s", timings["slow"])
+	}
+	wf.TakeStep(context.Background(), "panic", NewBaseEvent("panic", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if _, ok := timings["panic"]; !ok {
+		t.Error("Testing BaseWorkflow.OnStepTiming with a panicking step: expected the timing to be recorded")
+	}
+}
+
+func TestWorkflowPanicRecovery(t *testing.T) {
+	steps := map[string]StepFunc{
+		"panic": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			var m map[string]string
+			m["boom"] = "boom"
+			return NewBaseEvent("end", m)
+		},
+		"handleError": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "recovered: " + ev.Data["panic"]})
+		},
+	}
+	wf := NewBaseWorkflow("panic", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	outputs := []any{}
+	wf.Run(context.Background(), NewBaseEvent("panic", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, nil)
+	if len(outputs) != 1 || !strings.Contains(outputs[0].(string), "panicked") {
+		t.Errorf("Testing BaseWorkflow.Run with a panicking step: want the panic as output, got %v", outputs)
+	}
+
+	wf.ErrorStep = "handleError"
+	outputs = []any{}
+	wf.Run(context.Background(), NewBaseEvent("panic", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, nil)
+	if len(outputs) != 1 || !strings.HasPrefix(outputs[0].(string), "recovered: ") || !strings.Contains(outputs[0].(string), "nil map") {
+		t.Errorf("Testing BaseWorkflow.Run with ErrorStep: want the recovered output, got %v", outputs)
+	}
+	event := wf.TakeStep(context.Background(), "panic", NewBaseEvent("panic", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if _, ok := event.Get(ErrorKey); !ok || event.NextStep != "handleError" {
+		t.Errorf("Testing BaseWorkflow.TakeStep with a panicking step: want an error event routing to 'handleError', got %v", event)
+	}
+}
+
+func TestWorkflowAddStep(t *testing.T) {
+	wf := NewBaseWorkflow("greet", NewBaseContext(map[string]any{}, map[string]any{}), nil)
+	greet := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("answer", map[string]string{})
+	}
+	if err := wf.AddStep("greet", greet); err != nil {
+		t.Errorf("Testing BaseWorkflow.AddStep: unexpected error %v", err)
+	}
+	if err := wf.AddStep("answer", mockStep); err != nil {
+		t.Errorf("Testing BaseWorkflow.AddStep: unexpected error %v", err)
+	}
+	if err := wf.AddStep("answer", mockStep); err == nil {
+		t.Error("Testing BaseWorkflow.AddStep with a duplicate name: expected an error")
+	}
+	if err := wf.AddStep("end", mockStep); err == nil {
+		t.Error("Testing BaseWorkflow.AddStep with a reserved name: expected an error")
+	}
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate after AddStep: BaseWorkflow is not valid, but it should be: %v", err)
+	}
+	outputs := []any{}
+	wf.Run(context.Background(), NewBaseEvent("greet", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, nil)
+	if !slices.Equal(outputs, []any{"hello world"}) {
+		t.Errorf("Testing BaseWorkflow.Run after AddStep: want %v, got %v", []any{"hello world"}, outputs)
+	}
+	wf.RemoveStep("answer")
+	if _, ok := wf.Steps["answer"]; ok {
+		t.Error("Testing BaseWorkflow.RemoveStep: expected the step to be removed")
+	}
+	if err := wf.AddStep("answer", mockStep); err != nil {
+		t.Errorf("Testing BaseWorkflow.AddStep after RemoveStep: unexpected error %v", err)
+	}
+}
+
+func TestWorkflowReservedSteps(t *testing.T) {
+	wf := NewBaseWorkflow("first", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"first": mockStep, "retry": mockStep})
+	if !slices.Equal(wf.ReservedSteps, []string{"end"}) {
+		t.Errorf("Testing NewBaseWorkflow: want ReservedSteps %v, got %v", []string{"end"}, wf.ReservedSteps)
+	}
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate: BaseWorkflow is not valid, but it should be: %v", err)
+	}
+	wf.ReservedSteps = append(wf.ReservedSteps, "retry")
+	valid, err := wf.Validate()
+	if valid || err == nil || !strings.Contains(err.Error(), "`retry`") {
+		t.Errorf("Testing BaseWorkflow.Validate with a custom reserved step: want an error naming %q, got %v", "retry", err)
+	}
+	if err := wf.AddStep("retry", mockStep); err == nil || !strings.Contains(err.Error(), "`retry`") {
+		t.Errorf("Testing BaseWorkflow.AddStep with a custom reserved step: want an error naming %q, got %v", "retry", err)
+	}
+	delete(wf.Steps, "retry")
+	wf.Steps[""] = mockStep
+	if valid, err := wf.Validate(); valid || err == nil {
+		t.Error("Testing BaseWorkflow.Validate with an empty step name: expected an error")
+	}
+	if err := wf.AddStep("", mockStep); err == nil {
+		t.Error("Testing BaseWorkflow.AddStep with an empty step name: expected an error")
+	}
+}
+
+func TestWorkflowDefaultNextStep(t *testing.T) {
+	steps := map[string]StepFunc{
+		"forgetful": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("", map[string]string{"output": "forgot to route"})
+		},
+		"cleanup": mockStep,
+	}
+	wf := NewBaseWorkflow("forgetful", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	outputs := []any{}
+	onOutput := func(out any) { outputs = append(outputs, out) }
+	wf.Run(context.Background(), NewBaseEvent("forgetful", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, onOutput, nil)
+	if !slices.Equal(outputs, []any{"forgot to route"}) {
+		t.Errorf("Testing BaseWorkflow.Run with an empty NextStep: want %v, got %v", []any{"forgot to route"}, outputs)
+	}
+	wf.DefaultNextStep = "cleanup"
+	outputs = []any{}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	wf.Run(context.Background(), NewBaseEvent("forgetful", map[string]string{}), wfCtx, func(*BaseEvent) {}, func(*BaseEvent) {}, onOutput, nil)
+	if !slices.Equal(outputs, []any{"hello world"}) || !slices.Equal(wfCtx.ExecutionTrace(), []string{"forgetful", "cleanup", "end"}) {
+		t.Errorf("Testing BaseWorkflow.Run with DefaultNextStep: want %v, got %v (trace %v)", []any{"hello world"}, outputs, wfCtx.ExecutionTrace())
+	}
+	if event := wf.TakeStep(context.Background(), "", NewBaseEvent("", map[string]string{}), wfCtx); event.Data["output"] != "hello world" {
+		t.Errorf("Testing BaseWorkflow.TakeStep with an empty name: Expected 'hello world', gotten %v", event.Data["output"])
+	}
+	wf.Transitions = map[string][]string{"forgetful": {""}}
+	if valid, err := wf.Validate(); valid || err == nil {
+		t.Error("Testing BaseWorkflow.Validate with a transition to an empty name: expected an error")
+	}
+	wf.Transitions = nil
+	wf.DefaultNextStep = "missing"
+	if valid, err := wf.Validate(); valid || err == nil {
+		t.Error("Testing BaseWorkflow.Validate with an unregistered DefaultNextStep: expected an error")
+	}
+	wf.DefaultNextStep = "cleanup"
+	wf.ErrorStep = "recvoer"
+	if valid, err := wf.Validate(); valid || !errors.Is(err, ErrStepNotFound) || !strings.Contains(err.Error(), "recvoer") {
+		t.Errorf("Testing BaseWorkflow.Validate with an unregistered ErrorStep: want ErrStepNotFound naming %q, got %v", "recvoer", err)
+	}
+	for _, errorStep := range []string{"cleanup", "end"} {
+		wf.ErrorStep = errorStep
+		if valid, err := wf.Validate(); !valid {
+			t.Errorf("Testing BaseWorkflow.Validate with ErrorStep %s: BaseWorkflow is not valid, but it should be: %v", errorStep, err)
+		}
+	}
+}
+
+func TestWorkflowMiddleware(t *testing.T) {
+	steps := map[string]StepFunc{
+		"first": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("second", map[string]string{})
+		},
+		"second": mockStep,
+	}
+	wf := NewBaseWorkflow("first", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	calls := []string{}
+	timings := 0
+	wf.Use(func(next StepFunc) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			start := time.Now()
+			calls = append(calls, "timing:before")
+			out := next(ctx, ev, wfCtx)
+			calls = append(calls, "timing:after")
+			if time.Since(start) >= 0 {
+				timings++
+			}
+			return out
+		}
+	})
+	wf.Use(func(next StepFunc) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			calls = append(calls, "logging:"+ev.NextStep)
+			return next(ctx, ev, wfCtx)
+		}
+	})
+	wf.Run(context.Background(), NewBaseEvent("first", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(any) {}, nil)
+	want := []string{"timing:before", "logging:first", "timing:after", "timing:before", "logging:second", "timing:after"}
+	if !slices.Equal(calls, want) || timings != 2 {
+		t.Errorf("Testing BaseWorkflow.Use: want %v and 2 timings, got %v and %d", want, calls, timings)
+	}
+}
+
+func TestWorkflowAddPreprocessor(t *testing.T) {
+	steps := map[string]StepFunc{
+		"greet": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "hello " + ev.Data["name"]})
+		},
+	}
+	wf := NewBaseWorkflow("greet", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.AddPreprocessor(func(ev *BaseEvent) *BaseEvent {
+		ev.Data["name"] = strings.TrimSpace(ev.Data["name"])
+		return ev
+	})
+	wf.AddPreprocessor(func(ev *BaseEvent) *BaseEvent {
+		ev.Data["name"] = strings.ToUpper(ev.Data["name"])
+		return ev
+	})
+	input := NewBaseEvent("greet", map[string]string{"name": "  ada "})
+	out, err := wf.RunSync(context.Background(), input, NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "hello ADA" {
+		t.Errorf("Testing BaseWorkflow.AddPreprocessor: want 'hello ADA' and no error, got %v and %v", out, err)
+	}
+	if input.Data["name"] != "  ada " {
+		t.Errorf("Testing BaseWorkflow.AddPreprocessor: the input event should be left untouched, got %q", input.Data["name"])
+	}
+}
+
+func TestWorkflowStepResolver(t *testing.T) {
+	model := func(name string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": name})
+		}
+	}
+	resolver := func(stepName string, wfCtx *BaseContext) StepFunc {
+		if cheap, _ := wfCtx.GetValue("cheap"); stepName == "generate" && cheap == true {
+			return model("cheap model")
+		}
+		return nil
+	}
+	wf := NewBaseWorkflow("generate", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"generate": model("expensive model")}, WithStepResolver(resolver))
+	for cheap, want := range map[bool]string{true: "cheap model", false: "expensive model"} {
+		out, err := wf.RunSync(context.Background(), NewBaseEvent("generate", map[string]string{}), NewBaseContext(map[string]any{"cheap": cheap}, map[string]any{}))
+		if err != nil || out != want {
+			t.Errorf("Testing BaseWorkflow.StepResolver with cheap=%v: want %q and no error, got %v and %v", cheap, want, out, err)
+		}
+	}
+}
+
+func TestWorkflowDisableStep(t *testing.T) {
+	step := func(name, next string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent(next, map[string]string{"output": ev.Data["output"] + name})
+		}
+	}
+	steps := map[string]StepFunc{"draft": step("draft ", "review"), "review": step("review ", "publish"), "publish": step("publish", "end")}
+	wf := NewBaseWorkflow("draft", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	if err := wf.DisableStep("translate", "publish"); !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing BaseWorkflow.DisableStep with an unknown step: want ErrStepNotFound, got %v", err)
+	}
+	if err := wf.DisableStep("review", "publish"); err != nil {
+		t.Fatalf("Testing BaseWorkflow.DisableStep: unexpected error %v", err)
+	}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("draft", map[string]string{}), wfCtx)
+	if err != nil || out != "draft publish" || wf.StepEnabled("review") {
+		t.Errorf("Testing BaseWorkflow.DisableStep: want 'draft publish' and no error, got %v and %v", out, err)
+	}
+	if trace := wfCtx.ExecutionTrace(); !slices.Equal(trace, []string{"draft", "review", "publish", "end"}) {
+		t.Errorf("Testing BaseWorkflow.DisableStep: unexpected execution trace %v", trace)
+	}
+	wf.DisableStep("review", "")
+	if out, _ := wf.RunSync(context.Background(), NewBaseEvent("draft", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{})); out != "draft " {
+		t.Errorf("Testing BaseWorkflow.DisableStep without a skip target: want 'draft ', got %v", out)
+	}
+	wf.EnableStep("review")
+	if out, _ := wf.RunSync(context.Background(), NewBaseEvent("draft", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{})); out != "draft review publish" {
+		t.Errorf("Testing BaseWorkflow.EnableStep: want 'draft review publish', got %v", out)
+	}
+}
+
+func TestWorkflowOutputKeys(t *testing.T) {
+	steps := map[string]StepFunc{
+		"research": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"summary": "Go is fun", "citations": "go.dev", "scratch": "notes"})
+		},
+	}
+	wf := NewBaseWorkflow("research", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.OutputKeys = []string{"summary", "citations", "confidence"}
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("research", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	want := map[string]any{"summary": "Go is fun", "citations": "go.dev"}
+	if outputs, ok := out.(map[string]any); err != nil || !ok || !maps.Equal(outputs, want) {
+		t.Errorf("Testing BaseWorkflow.OutputKeys: want %v and no error, got %v and %v", want, out, err)
+	}
+}
+
+func TestWorkflowMaxDuration(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	executed := []string{}
+	slow := func(name, next string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			executed = append(executed, name)
+			clock.Advance(40 * time.Millisecond)
+			return NewBaseEvent(next, map[string]string{"output": "done"})
+		}
+	}
+	steps := map[string]StepFunc{"first": slow("first", "second"), "second": slow("second", "third"), "third": slow("third", "end")}
+	wf := NewBaseWorkflow("first", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.MaxDuration = 50 * time.Millisecond
+	_, err := wf.RunSync(context.Background(), NewBaseEvent("first", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}, WithClock(clock)))
+	if !errors.Is(err, ErrMaxDurationExceeded) || !slices.Equal(executed, []string{"first", "second"}) {
+		t.Errorf("Testing BaseWorkflow.MaxDuration: want the run to stop before 'third' with ErrMaxDurationExceeded, got %v after %v", err, executed)
+	}
+	wf.MaxDuration = 0
+	if out, err := wf.RunSync(context.Background(), NewBaseEvent("first", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}, WithClock(clock))); err != nil || out != "done" {
+		t.Errorf("Testing BaseWorkflow.MaxDuration set to 0: want 'done' and no error, got %v and %v", out, err)
+	}
+}
+
+func TestWorkflowRunSync(t *testing.T) {
+	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"firstStep": mockStep})
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("firstStep", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "hello world" {
+		t.Errorf("Testing BaseWorkflow.RunSync: want 'hello world' and no error, got %v and %v", out, err)
+	}
+	wf.FirstStep = "missing"
+	out, err = wf.RunSync(context.Background(), NewBaseEvent("missing", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err == nil || out != nil {
+		t.Errorf("Testing BaseWorkflow.RunSync with a missing step: want nil and an error, got %v and %v", out, err)
+	}
+}
+
+func TestWorkflowDryRun(t *testing.T) {
+	called := false
+	sideEffect := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		called = true
+		return NewBaseEvent("end", map[string]string{})
+	}
+	wf := NewBaseWorkflow("first", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"first": sideEffect, "second": sideEffect})
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	stub := map[string]*BaseEvent{
+		"first":  NewBaseEvent("second", map[string]string{}),
+		"second": NewBaseEvent("end", map[string]string{"output": "done"}),
+	}
+	visited := wf.DryRun(NewBaseEvent("first", map[string]string{}), wfCtx, stub)
+	if !slices.Equal(visited, []string{"first", "second", "end"}) {
+		t.Errorf("Testing BaseWorkflow.DryRun: want [first second end], got %v", visited)
+	}
+	if called {
+		t.Error("Testing BaseWorkflow.DryRun: expected no step to be executed")
+	}
+	if trace := wfCtx.ExecutionTrace(); len(trace) != 0 {
+		t.Errorf("Testing BaseWorkflow.DryRun: expected the context to be left untouched, got trace %v", trace)
+	}
+	delete(stub, "second")
+	visited = wf.DryRun(NewBaseEvent("first", map[string]string{}), nil, stub)
+	if !slices.Equal(visited, []string{"first"}) {
+		t.Errorf("Testing BaseWorkflow.DryRun with a missing stub: want [first], got %v", visited)
+	}
+}
+
+func TestWorkflowOnError(t *testing.T) {
+	steps := map[string]StepFunc{
+		"lost": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("unknown", map[string]string{})
+		},
+		"panic": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			panic("boom")
+		},
+	}
+	wf := NewBaseWorkflow("lost", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	failures := map[string]error{}
+	outputs := []any{}
+	onError := func(stepName string, err error) { failures[stepName] = err }
+	wf.Run(context.Background(), NewBaseEvent("lost", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, onError)
+	if err, ok := failures["unknown"]; !ok || !strings.Contains(err.Error(), "does not exist") || len(outputs) != 0 {
+		t.Errorf("Testing BaseWorkflow.Run with an unknown NextStep: want the error on onError and no output, got %v and %v", failures, outputs)
+	}
+
+	wf.FirstStep = "panic"
+	wf.Run(context.Background(), NewBaseEvent("panic", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, onError)
+	var panicErr *PanicError
+	if !errors.As(failures["panic"], &panicErr) || panicErr.Value != "boom" || len(outputs) != 1 {
+		t.Errorf("Testing BaseWorkflow.Run with a panicking step: want a *PanicError on onError and the panic output, got %v and %v", failures, outputs)
+	}
+}
+
+func TestWorkflowStepNames(t *testing.T) {
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"retrieve": mockStep, "generate": mockStep, "answer": mockStep})
+	if names := wf.StepNames(); !slices.Equal(names, []string{"answer", "generate", "retrieve"}) {
+		t.Errorf("Testing BaseWorkflow.StepNames: want %v, got %v", []string{"answer", "generate", "retrieve"}, names)
+	}
+	if !wf.HasStep("generate") || wf.HasStep("end") {
+		t.Error("Testing BaseWorkflow.HasStep: want true for 'generate' and false for 'end'")
+	}
+	ordered := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{})
+	for _, name := range []string{"retrieve", "generate", "answer", "cite"} {
+		if err := ordered.AddStep(name, mockStep); err != nil {
+			t.Fatalf("Testing BaseWorkflow.AddStep: unexpected error %v", err)
+		}
+	}
+	ordered.RemoveStep("answer")
+	ordered.Steps["direct"] = mockStep
+	if names := ordered.StepNames(); !slices.Equal(names, []string{"retrieve", "generate", "cite", "direct"}) {
+		t.Errorf("Testing BaseWorkflow.StepNames in registration order: want %v, got %v", []string{"retrieve", "generate", "cite", "direct"}, names)
+	}
+	ordered.Steps[""] = mockStep
+	ordered.Steps["end"] = mockStep
+	for i := 0; i < 10; i++ {
+		if _, err := ordered.Validate(); err == nil || err.Error() != "steps cannot have an empty name" {
+			t.Fatalf("Testing BaseWorkflow.Validate: want a stable error for the first invalid step, got %v", err)
+		}
+	}
+}
+
+func TestWorkflowResetContext(t *testing.T) {
+	count := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		runs, _ := wfCtx.GetValue("runs")
+		n, _ := runs.(int)
+		wfCtx.StoreValue("runs", n+1)
+		wfCtx.SetState(map[string]any{"lastRun": n + 1})
+		return NewBaseEvent("end", map[string]string{"output": fmt.Sprint(n + 1)})
+	}
+	wf := NewBaseWorkflow("count", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"count": count})
+	for _, want := range []string{"1", "2"} {
+		if out, _ := wf.RunSync(context.Background(), NewBaseEvent("count", map[string]string{}), wf.Context); out != want {
+			t.Errorf("Testing BaseWorkflow.RunSync with a shared context: want %s, got %v", want, out)
+		}
+	}
+	wf.ResetContextState()
+	if _, ok := wf.Context.GetState()["lastRun"]; ok {
+		t.Errorf("Testing BaseWorkflow.ResetContextState: want an empty State, got %v", wf.Context.GetState())
+	}
+	if runs, _ := wf.Context.GetValue("runs"); runs != 2 {
+		t.Errorf("Testing BaseWorkflow.ResetContextState: want the Store to be kept, got %v", runs)
+	}
+	wf.ResetContext()
+	if out, _ := wf.RunSync(context.Background(), NewBaseEvent("count", map[string]string{}), wf.Context); out != "1" {
+		t.Errorf("Testing BaseWorkflow.ResetContext: want no leftover state from previous runs, got %v", out)
+	}
+}
+
+func TestWorkflowStepHooks(t *testing.T) {
+	retrieve := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if ev.Data["retry"] == "" {
+			return NewBaseEvent("retrieve", map[string]string{"retry": "yes"})
+		}
+		return NewBaseEvent("generate", map[string]string{})
+	}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"retrieve": retrieve, "generate": mockStep})
+	calls := []string{}
+	wf.OnEnter("retrieve", func(ev *BaseEvent, wfCtx *BaseContext) { calls = append(calls, "enter:retrieve:first") })
+	wf.OnEnter("retrieve", func(ev *BaseEvent, wfCtx *BaseContext) { calls = append(calls, "enter:retrieve:second") })
+	wf.OnExit("generate", func(ev *BaseEvent, wfCtx *BaseContext) { calls = append(calls, "exit:generate:"+ev.NextStep) })
+	if _, err := wf.RunSync(context.Background(), NewBaseEvent("retrieve", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{})); err != nil {
+		t.Fatalf("Testing BaseWorkflow.OnEnter: unexpected error %v", err)
+	}
+	want := []string{"enter:retrieve:first", "enter:retrieve:second", "enter:retrieve:first", "enter:retrieve:second", "exit:generate:end"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("Testing BaseWorkflow.OnEnter and OnExit: want %v, got %v", want, calls)
+	}
+}
+
+func TestWorkflowEventMetadata(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	retrieve := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("generate", map[string]string{})
+	}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"retrieve": retrieve, "generate": mockStep})
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{}, WithClock(clock))
+	wf.RunWith(context.Background(), NewBaseEvent("retrieve", map[string]string{}), wfCtx)
+	emitted := wfCtx.History()[1:]
+	if len(emitted) != 2 {
+		t.Fatalf("Testing BaseEvent metadata: want 2 emitted events, got %d", len(emitted))
+	}
+	for i, source := range []string{"retrieve", "generate"} {
+		if emitted[i].SourceStep != source || !emitted[i].CreatedAt.Equal(clock.Now()) {
+			t.Errorf("Testing BaseEvent metadata: want SourceStep %s and CreatedAt %v, got %s and %v", source, clock.Now(), emitted[i].SourceStep, emitted[i].CreatedAt)
+		}
+	}
+	if input := wfCtx.History()[0]; input.SourceStep != "" || !input.CreatedAt.IsZero() {
+		t.Errorf("Testing BaseEvent metadata: want an unstamped input event, got %+v", input)
+	}
+}
+
+func TestWorkflowOnRunEnd(t *testing.T) {
+	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"firstStep": mockStep})
+	outcomes := []any{}
+	wf.OnRunEnd = func(output any, err error) {
+		if err != nil {
+			outcomes = append(outcomes, "failed")
+			return
+		}
+		outcomes = append(outcomes, output)
+	}
+	wf.RunSync(context.Background(), NewBaseEvent("firstStep", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	wf.DryRun(NewBaseEvent("firstStep", map[string]string{}), nil, map[string]*BaseEvent{"firstStep": NewBaseEvent("end", map[string]string{})})
+	wf.FirstStep = "missing"
+	wf.RunSync(context.Background(), NewBaseEvent("missing", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if !slices.Equal(outcomes, []any{"hello world", "failed"}) {
+		t.Errorf("Testing BaseWorkflow.OnRunEnd: want %v, got %v", []any{"hello world", "failed"}, outcomes)
+	}
+}
+
+func TestWorkflowOutputKeyTerminalStep(t *testing.T) {
+	steps := map[string]StepFunc{
+		"answer": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("done", map[string]string{"result": "42", "output": "domain value"})
+		},
+	}
+	wf := NewBaseWorkflow("answer", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wf.OutputKey = "result"
+	wf.TerminalStep = "done"
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("answer", map[string]string{}), wfCtx)
+	if err != nil || out != "42" {
+		t.Errorf("Testing BaseWorkflow.OutputKey and TerminalStep: want '42' and no error, got %v and %v", out, err)
+	}
+	if trace := wfCtx.ExecutionTrace(); !slices.Equal(trace, []string{"answer", "done"}) {
+		t.Errorf("Testing BaseWorkflow.TerminalStep: want trace %v, got %v", []string{"answer", "done"}, trace)
+	}
+	if err := wf.AddStep("done", mockStep); err == nil {
+		t.Error("Testing BaseWorkflow.TerminalStep: expected the terminal step name to be reserved")
+	}
+	if valid, err := wf.Validate(); !valid {
+		t.Errorf("Testing BaseWorkflow.Validate with TerminalStep: BaseWorkflow is not valid, but it should be: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// MapReduce returns a step that processes the collection held by the
+// incoming event under itemsKey, as a JSON array: every item is passed to
+// mapFn, on up to concurrency goroutines (sequentially if concurrency is 1
+// or less), and the mapped items are then folded with reduceFn, in the
+// order of the collection, starting from a nil accumulator.
+//
+// The emitted event holds the Data of the incoming event with the result
+// under resultKey, stored like the results of ToolStep: strings as they
+// are, any other value encoded as JSON. Its NextStep is left empty, so
+// that it routes to the DefaultNextStep of the workflow or to the step
+// wired by a Flow. An empty or missing collection results in a nil value,
+// stored as "null", without invoking mapFn or reduceFn. If the collection
+// is not a JSON array, or if mapFn panics, whatever the concurrency, the
+// step routes to 'end' with an error under ErrorKey and as output.
+func MapReduce(mapFn func(item any, ctx *BaseContext) any, reduceFn func(acc, item any) any, itemsKey, resultKey string, concurrency int) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		var items []any
+		if raw, ok := ev.Data[itemsKey]; ok && raw != "" {
+			if err := json.Unmarshal([]byte(raw), &items); err != nil {
+				message := fmt.Sprintf("the value under %s is not a JSON array: %v", itemsKey, err)
+				return NewBaseEvent("end", map[string]string{
+					ErrorKey: message,
+					"output": message,
+				})
+			}
+		}
+		mapped, err := mapItems(items, wfCtx, mapFn, concurrency)
+		if err != nil {
+			return NewBaseEvent("end", map[string]string{
+				ErrorKey: err.Error(),
+				"output": err.Error(),
+			})
+		}
+		var result any
+		for _, item := range mapped {
+			result = reduceFn(result, item)
+		}
+		data := maps.Clone(ev.Data)
+		if data == nil {
+			data = map[string]string{}
+		}
+		encoded, ok := result.(string)
+		if !ok {
+			raw, err := json.Marshal(result)
+			if err != nil {
+				message := fmt.Sprintf("cannot encode the result under %s: %v", resultKey, err)
+				return NewBaseEvent("end", map[string]string{
+					ErrorKey: message,
+					"output": message,
+				})
+			}
+			encoded = string(raw)
+		}
+		data[resultKey] = encoded
+		return NewBaseEvent("", data)
+	}
+}
+
+// mapItems applies mapFn to every item on up to concurrency goroutines,
+// returning the mapped items in the order of items, or an error reporting
+// the first item whose mapping panicked.
+func mapItems(items []any, wfCtx *BaseContext, mapFn func(item any, ctx *BaseContext) any, concurrency int) ([]any, error) {
+	mapped := make([]any, len(items))
+	errs := make([]error, len(items))
+	if concurrency <= 1 {
+		for i := range items {
+			mapped[i], errs[i] = mapItem(mapFn, i, items[i], wfCtx)
+		}
+		return mapped, firstError(errs)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				mapped[i], errs[i] = mapItem(mapFn, i, items[i], wfCtx)
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return mapped, firstError(errs)
+}
+
+// mapItem applies mapFn to the item at index i, recovering its panics.
+func mapItem(mapFn func(item any, ctx *BaseContext) any, i int, item any, wfCtx *BaseContext) (mapped any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("mapping item %d panicked: %v", i, r)
+		}
+	}()
+	return mapFn(item, wfCtx), nil
+}
+
+// firstError returns the first non-nil error of errs.
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package workflowsgo
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMapReduce(t *testing.T) {
+	double := func(item any, wfCtx *BaseContext) any { return item.(float64) * 2 }
+	sum := func(acc, item any) any {
+		total, _ := acc.(float64)
+		return total + item.(float64)
+	}
+	for _, concurrency := range []int{1, 3} {
+		step := MapReduce(double, sum, "numbers", "total", concurrency)
+		result := step(context.Background(), NewBaseEvent("sum", map[string]string{"numbers": "[1, 2, 3, 4]", "unit": "kg"}), NewBaseContext(map[string]any{}, map[string]any{}))
+		if result.Data["total"] != "20" || result.Data["unit"] != "kg" || result.NextStep != "" {
+			t.Errorf("Testing MapReduce with concurrency %d: want a total of 20 and the incoming Data, got %v", concurrency, result)
+		}
+	}
+	step := MapReduce(double, sum, "numbers", "total", 2)
+	if result := step(context.Background(), NewBaseEvent("sum", map[string]string{"numbers": "[]"}), NewBaseContext(map[string]any{}, map[string]any{})); result.Data["total"] != "null" {
+		t.Errorf("Testing MapReduce with an empty collection: want 'null', got %v", result.Data)
+	}
+	if result := step(context.Background(), NewBaseEvent("sum", map[string]string{"numbers": "1, 2"}), NewBaseContext(map[string]any{}, map[string]any{})); result.NextStep != "end" || result.Data[ErrorKey] == "" {
+		t.Errorf("Testing MapReduce with a malformed collection: want an error event, got %v", result)
+	}
+	for _, concurrency := range []int{1, 3} {
+		step := MapReduce(double, sum, "numbers", "total", concurrency)
+		result := step(context.Background(), NewBaseEvent("sum", map[string]string{"numbers": `[1, "two", 3]`}), NewBaseContext(map[string]any{}, map[string]any{}))
+		if result.NextStep != "end" || !strings.HasPrefix(result.Data[ErrorKey], "mapping item 1 panicked: ") {
+			t.Errorf("Testing MapReduce with a panicking mapFn and concurrency %d: want an error event, got %v", concurrency, result)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package workflowsgo
+
+import "context"
+
+// Memoize wraps a deterministic step so that it runs once per input: the
+// event it returns is cached under keyFn(ev), and later invocations with an
+// event mapping to the same key return a copy of the cached event without
+// invoking step.
+//
+// The cache belongs to the returned step, so it is shared by every run and
+// context that execute it. It holds at most maxEntries events, evicting the
+// least recently used one when full; a maxEntries of 0 or less leaves it
+// unbounded. Events carrying a value under ErrorKey are not cached, so that
+// failures are retried.
+func Memoize(step StepFunc, keyFn func(*BaseEvent) string, maxEntries int) StepFunc {
+	cache := newLRUCache[*BaseEvent](maxEntries)
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		key := keyFn(ev)
+		if cached, ok := cache.get(key); ok {
+			return cached.Clone()
+		}
+		result := step(ctx, ev, wfCtx)
+		if result != nil && !isFailed(result) {
+			cache.add(key, result.Clone())
+		}
+		return result
+	}
+}
@@ -0,0 +1,32 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoize(t *testing.T) {
+	calls := map[string]int{}
+	square := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		calls[ev.Data["n"]]++
+		return NewBaseEvent("end", map[string]string{"output": ev.Data["n"] + "^2"})
+	}
+	step := Memoize(square, func(ev *BaseEvent) string { return ev.Data["n"] }, 2)
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	run := func(n string) *BaseEvent {
+		return step(context.Background(), NewBaseEvent("square", map[string]string{"n": n}), wfCtx)
+	}
+	first, second := run("3"), run("3")
+	if calls["3"] != 1 || second.Data["output"] != "3^2" || first == second {
+		t.Errorf("Testing Memoize with a repeated input: want a single call and a copy of the cached event, got %d calls and %v", calls["3"], second.Data)
+	}
+	run("4")
+	run("3")
+	run("5")
+	if run("3"); calls["3"] != 1 {
+		t.Errorf("Testing Memoize: want '3' to stay cached as recently used, got %d calls", calls["3"])
+	}
+	if run("4"); calls["4"] != 2 {
+		t.Errorf("Testing Memoize with a full cache: want '4' evicted and recomputed, got %d calls", calls["4"])
+	}
+}
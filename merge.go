@@ -0,0 +1,25 @@
+package workflowsgo
+
+// MergeEvents returns a new event combining the given ones, e.g. the events
+// produced by the branches of Parallel.
+//
+// The Data of the result is the union of the Data of all the events: when
+// several events hold the same key, the value of the event that comes last
+// in the argument list wins. The NextStep of the result is the last
+// non-empty NextStep among the events. Nil events are ignored, and the
+// events themselves are left unchanged.
+func MergeEvents(events ...*BaseEvent) *BaseEvent {
+	merged := NewBaseEvent("", map[string]string{})
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		for k, v := range ev.Data {
+			merged.Data[k] = v
+		}
+		if ev.NextStep != "" {
+			merged.NextStep = ev.NextStep
+		}
+	}
+	return merged
+}
@@ -0,0 +1,25 @@
+package workflowsgo
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMergeEvents(t *testing.T) {
+	first := NewBaseEvent("rank", map[string]string{"answer": "first", "source": "model-a"})
+	second := NewBaseEvent("", map[string]string{"answer": "second", "score": "0.9"})
+	merged := MergeEvents(first, nil, second)
+	want := map[string]string{"answer": "second", "source": "model-a", "score": "0.9"}
+	if !maps.Equal(merged.Data, want) {
+		t.Errorf("Testing MergeEvents: want %v, got %v", want, merged.Data)
+	}
+	if merged.NextStep != "rank" {
+		t.Errorf("Testing MergeEvents: Expected 'rank', gotten %s", merged.NextStep)
+	}
+	if first.Data["answer"] != "first" || len(first.Data) != 2 {
+		t.Errorf("Testing MergeEvents: the input events were modified: %v", first.Data)
+	}
+	if empty := MergeEvents(); empty.NextStep != "" || len(empty.Data) != 0 {
+		t.Errorf("Testing MergeEvents without events: want an empty event, got %v", empty)
+	}
+}
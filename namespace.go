@@ -0,0 +1,26 @@
+package workflowsgo
+
+// NamespaceSeparator separates the namespace from the key in the keys of
+// BaseContext.Store written by StoreValueNS.
+const NamespaceSeparator = "::"
+
+// namespacedKey returns the key of BaseContext.Store that holds key within
+// namespace.
+func namespacedKey(namespace, key string) string {
+	return namespace + NamespaceSeparator + key
+}
+
+// StoreValueNS stores a key-value pair in BaseContext.Store within
+// namespace, so that steps using different namespaces do not overwrite
+// each other's values. The value is stored under the plain key
+// namespace + NamespaceSeparator + key, where it can also be read with
+// GetValue; values stored with StoreValue are never part of a namespace.
+func (ctx *BaseContext) StoreValueNS(namespace, key string, val any) {
+	ctx.StoreValue(namespacedKey(namespace, key), val)
+}
+
+// GetValueNS fetches the value associated with a key within namespace, see
+// StoreValueNS.
+func (ctx *BaseContext) GetValueNS(namespace, key string) (any, bool) {
+	return ctx.GetValue(namespacedKey(namespace, key))
+}
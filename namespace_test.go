@@ -0,0 +1,28 @@
+package workflowsgo
+
+import "testing"
+
+func TestContextNamespaces(t *testing.T) {
+	ctx := NewBaseContext(map[string]any{}, map[string]any{})
+	ctx.StoreValueNS("retrieve", "result", "documents")
+	ctx.StoreValueNS("generate", "result", "answer")
+	ctx.StoreValue("result", "plain")
+	var tests = []struct {
+		namespace string
+		want      any
+	}{
+		{"retrieve", "documents"},
+		{"generate", "answer"},
+	}
+	for _, tt := range tests {
+		if val, ok := ctx.GetValueNS(tt.namespace, "result"); !ok || val != tt.want {
+			t.Errorf("Testing BaseContext.GetValueNS(%q): want %v, got %v", tt.namespace, tt.want, val)
+		}
+	}
+	if val, _ := ctx.GetValue("retrieve" + NamespaceSeparator + "result"); val != "documents" {
+		t.Errorf("Testing BaseContext.GetValue with a namespaced key: want documents, got %v", val)
+	}
+	if _, ok := ctx.GetValueNS("missing", "result"); ok {
+		t.Error("Testing BaseContext.GetValueNS with an unknown namespace: expected no value")
+	}
+}
@@ -0,0 +1,196 @@
+package workflowsgo
+
+import (
+	"context"
+	"time"
+)
+
+// RunOptions holds the callbacks of a run started with RunWith. Every
+// callback is optional: a nil OnStart, OnEnd or OnOutput is not invoked,
+// while a nil OnError makes the errors that stop the run reach OnOutput, as
+// described in Run.
+type RunOptions struct {
+	// OnStart is invoked when an event starts being processed.
+	OnStart func(*BaseEvent)
+	// OnEnd is invoked when a step emits a new event.
+	OnEnd func(*BaseEvent)
+	// OnOutput is invoked with the output of the workflow.
+	OnOutput func(any)
+	// OnError is invoked with the errors of the run.
+	OnError func(stepName string, err error)
+	// RunID is the identifier of the run, generated if empty, see
+	// BaseContext.RunID.
+	RunID string
+	// Pause, if set, lets the run be paused between steps.
+	Pause *PauseController
+
+	// compensate, if set, runs the compensations of the run when it fails.
+	compensate func()
+	// dryRun is set by DryRun, whose runs are not reported to OnRunEnd.
+	dryRun bool
+	// stop, if set, is closed by Runner.Shutdown to stop the run before
+	// its next step.
+	stop <-chan struct{}
+	// deadline, if set, is when the run exceeds MaxDuration, measured with
+	// clock.
+	deadline time.Time
+	clock    Clock
+	// buffer, if set, queues the callbacks of the run, see
+	// WithCallbackBuffer.
+	buffer *CallbackBuffer
+	// onRunEnd, if set, replaces OnRunEnd for the branches of a run that
+	// fans out, which collects their outcomes.
+	onRunEnd func(output any, err error)
+	// onResult, if set, receives the outcome of the run, see runResult. It
+	// is invoked synchronously, even when the callbacks are buffered.
+	onResult func(output any, err error)
+}
+
+// RunOption configures the RunOptions of a run started with RunWith.
+type RunOption func(*RunOptions)
+
+// WithOnStart sets the callback invoked when an event starts being
+// processed.
+func WithOnStart(cb func(*BaseEvent)) RunOption {
+	return func(opts *RunOptions) {
+		opts.OnStart = cb
+	}
+}
+
+// WithOnEnd sets the callback invoked when a step emits a new event.
+func WithOnEnd(cb func(*BaseEvent)) RunOption {
+	return func(opts *RunOptions) {
+		opts.OnEnd = cb
+	}
+}
+
+// WithOnOutput sets the callback invoked with the output of the workflow.
+func WithOnOutput(cb func(any)) RunOption {
+	return func(opts *RunOptions) {
+		opts.OnOutput = cb
+	}
+}
+
+// WithOnError sets the callback invoked with the errors of the run.
+func WithOnError(cb func(stepName string, err error)) RunOption {
+	return func(opts *RunOptions) {
+		opts.OnError = cb
+	}
+}
+
+// WithRunID sets the identifier of the run, e.g. to make retried runs
+// share the same identifier for idempotency.
+func WithRunID(id string) RunOption {
+	return func(opts *RunOptions) {
+		opts.RunID = id
+	}
+}
+
+// WithPauseController makes the run wait between steps while pc is paused.
+func WithPauseController(pc *PauseController) RunOption {
+	return func(opts *RunOptions) {
+		opts.Pause = pc
+	}
+}
+
+// RunWith runs the workflow through completion like Run, invoking only the
+// callbacks set with opts.
+func (wf *BaseWorkflow) RunWith(ctx context.Context, inputEvent *BaseEvent, wfCtx *BaseContext, opts ...RunOption) {
+	options := newRunOptions(opts...)
+	wf.start(ctx, inputEvent, wfCtx, wf.newSaga(wfCtx, options).resolve, options)
+}
+
+// newRunOptions applies opts, replacing the callbacks that are not set,
+// except OnError, with no-ops, and queueing them on the CallbackBuffer of
+// the run, if any.
+func newRunOptions(opts ...RunOption) *RunOptions {
+	options := &RunOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.OnStart == nil {
+		options.OnStart = func(*BaseEvent) {}
+	}
+	if options.OnEnd == nil {
+		options.OnEnd = func(*BaseEvent) {}
+	}
+	if options.OnOutput == nil {
+		options.OnOutput = func(any) {}
+	}
+	if options.buffer != nil {
+		options.buffered(options.buffer)
+	}
+	return options
+}
+
+// fail reports an error that stops a run at stepName to OnError, or to
+// OnOutput when OnError is nil, after running the compensations of the run.
+func (wf *BaseWorkflow) fail(opts *RunOptions, stepName string, err error) {
+	if opts.compensate != nil {
+		opts.compensate()
+	}
+	if opts.OnError == nil {
+		opts.OnOutput(err)
+	} else {
+		opts.OnError(stepName, err)
+	}
+	wf.endRun(opts, nil, err)
+}
+
+// endRun reports the outcome of a run to OnRunEnd, unless it is a dry run.
+func (wf *BaseWorkflow) endRun(opts *RunOptions, output any, err error) {
+	if opts.onRunEnd != nil {
+		opts.onRunEnd(output, err)
+		return
+	}
+	if opts.onResult != nil {
+		opts.onResult(output, err)
+	}
+	if wf.OnRunEnd != nil && !opts.dryRun {
+		wf.OnRunEnd(output, err)
+	}
+}
+
+// runResult records the outcome of a run, for the helpers that return it
+// instead of passing it to callbacks, such as RunSync.
+type runResult struct {
+	output any
+	err    error
+}
+
+// option returns the RunOption recording the outcome of the run in res.
+func (res *runResult) option() RunOption {
+	return func(opts *RunOptions) {
+		opts.onResult = func(output any, err error) {
+			res.output, res.err = output, err
+		}
+	}
+}
+
+// get returns the output of the run, or the error that stopped it, which
+// is either the error passed to OnRunEnd or an error output.
+func (res *runResult) get() (any, error) {
+	if res.err != nil {
+		return nil, res.err
+	}
+	if err, ok := res.output.(error); ok {
+		return nil, err
+	}
+	return res.output, nil
+}
+
+// report handles the result of a step resolved within a run, reporting err
+// if it is not nil. It returns whether the run can go on with event.
+func (wf *BaseWorkflow) report(opts *RunOptions, stepName string, event *BaseEvent, err error) bool {
+	if err == nil {
+		return true
+	}
+	if event == nil {
+		wf.fail(opts, stepName, err)
+		return false
+	}
+	if opts.OnError != nil {
+		opts.OnError(stepName, err)
+	}
+	return true
+}
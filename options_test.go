@@ -0,0 +1,45 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestWorkflowRunWith(t *testing.T) {
+	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"firstStep": mockStep})
+	outputs := []any{}
+	wf.RunWith(context.Background(), NewBaseEvent("firstStep", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), WithOnOutput(func(out any) {
+		outputs = append(outputs, out)
+	}))
+	if !slices.Equal(outputs, []any{"hello world"}) {
+		t.Errorf("Testing BaseWorkflow.RunWith: want %v, got %v", []any{"hello world"}, outputs)
+	}
+
+	started, failures := 0, []string{}
+	wf.FirstStep = "missing"
+	wf.RunWith(context.Background(), NewBaseEvent("missing", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), WithOnStart(func(*BaseEvent) { started++ }), WithOnError(func(stepName string, err error) {
+		failures = append(failures, stepName)
+	}))
+	if started != 0 || !slices.Equal(failures, []string{"missing"}) {
+		t.Errorf("Testing BaseWorkflow.RunWith with a missing step: want no started event and a failure on 'missing', got %d and %v", started, failures)
+	}
+}
+
+func TestWorkflowRunID(t *testing.T) {
+	wf := NewBaseWorkflow("firstStep", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"firstStep": mockStep})
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	ids := []string{}
+	for i := 0; i < 2; i++ {
+		wf.RunWith(context.Background(), NewBaseEvent("firstStep", map[string]string{}), wfCtx, WithOnOutput(func(any) {
+			ids = append(ids, wfCtx.RunID())
+		}))
+	}
+	if len(ids) != 2 || len(ids[0]) != 36 || ids[0] == ids[1] {
+		t.Errorf("Testing BaseContext.RunID: want two distinct UUIDs, got %v", ids)
+	}
+	wf.RunWith(context.Background(), NewBaseEvent("firstStep", map[string]string{}), wfCtx, WithRunID("order-42"))
+	if id := wfCtx.RunID(); id != "order-42" {
+		t.Errorf("Testing WithRunID: want order-42, got %s", id)
+	}
+}
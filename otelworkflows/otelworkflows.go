@@ -0,0 +1,58 @@
+// otelworkflows integrates workflows-go with OpenTelemetry tracing.
+//
+// It lives in its own package so that the core workflowsgo package does not
+// depend on OpenTelemetry: importing otelworkflows is the opt-in.
+package otelworkflows
+
+import (
+	"context"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StepTracer is an implementation of workflowsgo.StepTracer that starts an
+// OpenTelemetry span for every executed step. Spans are named after the
+// step and are children of the span carried by the context.Context passed
+// to Run; steps receive the context.Context holding their own span, so that
+// the spans they start are nested under it.
+type StepTracer struct {
+	Tracer trace.Tracer
+}
+
+// NewStepTracer is a constructor that, given an OpenTelemetry tracer, returns a StepTracer.
+func NewStepTracer(tracer trace.Tracer) *StepTracer {
+	return &StepTracer{
+		Tracer: tracer,
+	}
+}
+
+// StartStep starts the span of a step, recording its index as the
+// workflow.step.index attribute. The returned function records the name of
+// the following step as the workflow.step.next attribute, or the error that
+// prevented the step from being executed, and ends the span.
+func (st *StepTracer) StartStep(ctx context.Context, stepName string, stepIndex int) (context.Context, func(*workflowsgo.BaseEvent, error)) {
+	ctx, span := st.Tracer.Start(ctx, stepName, trace.WithAttributes(
+		attribute.String("workflow.step.name", stepName),
+		attribute.Int("workflow.step.index", stepIndex),
+	))
+	return ctx, func(ev *workflowsgo.BaseEvent, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if ev != nil {
+			span.SetAttributes(attribute.String("workflow.step.next", ev.NextStep))
+		}
+		span.End()
+	}
+}
+
+// WithTracing is a workflowsgo.WorkflowOption that enables OpenTelemetry
+// tracing of the steps of a workflow with the given tracer.
+func WithTracing(tracer trace.Tracer) workflowsgo.WorkflowOption {
+	return func(wf *workflowsgo.BaseWorkflow) {
+		wf.Tracer = NewStepTracer(tracer)
+	}
+}
@@ -0,0 +1,65 @@
+package otelworkflows
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("workflows-go")
+
+	steps := map[string]workflowsgo.StepFunc{
+		"retrieve": func(ctx context.Context, ev *workflowsgo.BaseEvent, wfCtx *workflowsgo.BaseContext) *workflowsgo.BaseEvent {
+			_, span := tracer.Start(ctx, "vectorSearch")
+			span.End()
+			return workflowsgo.NewBaseEvent("generate", map[string]string{})
+		},
+		"generate": func(ctx context.Context, ev *workflowsgo.BaseEvent, wfCtx *workflowsgo.BaseContext) *workflowsgo.BaseEvent {
+			return workflowsgo.NewBaseEvent("end", map[string]string{"output": "hello world"})
+		},
+	}
+	wf := workflowsgo.NewBaseWorkflow("retrieve", workflowsgo.NewBaseContext(map[string]any{}, map[string]any{}), steps, WithTracing(tracer))
+
+	ctx, root := tracer.Start(context.Background(), "run")
+	wf.Run(ctx, workflowsgo.NewBaseEvent("retrieve", map[string]string{}), workflowsgo.NewBaseContext(map[string]any{}, map[string]any{}), func(*workflowsgo.BaseEvent) {}, func(*workflowsgo.BaseEvent) {}, func(any) {}, nil)
+	root.End()
+
+	spans := recorder.Ended()
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	if !slices.Equal(names, []string{"vectorSearch", "retrieve", "generate", "run"}) {
+		t.Fatalf("Testing WithTracing: unexpected spans %v", names)
+	}
+	rootID := spans[3].SpanContext().SpanID()
+	if spans[1].Parent().SpanID() != rootID || spans[2].Parent().SpanID() != rootID {
+		t.Error("Testing WithTracing: expected the step spans to be children of the run span")
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("Testing WithTracing: expected the span started by a step to be a child of the step span")
+	}
+	want := map[attribute.Key]attribute.Value{
+		"workflow.step.index": attribute.IntValue(2),
+		"workflow.step.next":  attribute.StringValue("end"),
+	}
+	for _, attr := range spans[2].Attributes() {
+		if expected, ok := want[attr.Key]; ok {
+			if attr.Value != expected {
+				t.Errorf("Testing WithTracing: want %s=%v, got %v", attr.Key, expected.Emit(), attr.Value.Emit())
+			}
+			delete(want, attr.Key)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("Testing WithTracing: missing attributes %v", want)
+	}
+}
@@ -0,0 +1,75 @@
+package workflowsgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingKeys is returned by ParseJSONInto, wrapped with the names of
+// the keys, when some of the requested keys are not in the JSON object.
+var ErrMissingKeys = errors.New("missing keys")
+
+// ParseJSONInto parses jsonStr, typically the response of a model asked
+// for structured output, as a JSON object and copies the values under keys
+// into the Data of ev, or all of its values if no keys are given. Strings
+// are stored as they are, any other value, null included, as JSON; numbers
+// keep the digits of the response. A Markdown code fence around the object,
+// as models often add, is stripped before parsing.
+//
+// If jsonStr is not a JSON object, an error is returned and ev is left
+// unchanged. If some keys are missing, the others are still copied and
+// the returned error wraps ErrMissingKeys, listing the missing keys.
+func ParseJSONInto(ev *BaseEvent, jsonStr string, keys ...string) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stripCodeFence(jsonStr)), &object); err != nil {
+		return fmt.Errorf("cannot parse the response as a JSON object: %w", err)
+	}
+	if object == nil {
+		return errors.New("cannot parse the response as a JSON object: got null")
+	}
+	if len(keys) == 0 {
+		keys = sortedKeys(object)
+	}
+	if ev.Data == nil {
+		ev.Data = map[string]string{}
+	}
+	var missing []string
+	for _, key := range keys {
+		raw, ok := object[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		var s string
+		if len(raw) > 0 && raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+			ev.Data[key] = s
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return fmt.Errorf("cannot parse the value under %s: %w", key, err)
+		}
+		ev.Data[key] = compact.String()
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingKeys, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// stripCodeFence removes the Markdown code fence, with an optional
+// language tag such as json, around s.
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") || len(s) < 6 {
+		return s
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "```"), "```")
+	if newline := strings.IndexByte(s, '\n'); newline >= 0 && !strings.ContainsAny(s[:newline], "{[") {
+		s = s[newline+1:]
+	}
+	return strings.TrimSpace(s)
+}
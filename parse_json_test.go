@@ -0,0 +1,36 @@
+package workflowsgo
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestParseJSONInto(t *testing.T) {
+	response := "```json\n{\"sentiment\": \"positive\", \"score\": 0.97, \"topics\": [\"go\", \"workflows\"], \"reason\": null}\n```"
+	ev := NewBaseEvent("classify", map[string]string{"text": "I love Go"})
+	if err := ParseJSONInto(ev, response, "sentiment", "score", "topics"); err != nil {
+		t.Fatalf("Testing ParseJSONInto: unexpected error %v", err)
+	}
+	want := map[string]string{"text": "I love Go", "sentiment": "positive", "score": "0.97", "topics": `["go","workflows"]`}
+	if !maps.Equal(ev.Data, want) {
+		t.Errorf("Testing ParseJSONInto: want %v, got %v", want, ev.Data)
+	}
+	ev = NewBaseEvent("classify", map[string]string{})
+	err := ParseJSONInto(ev, `{"sentiment": "negative", "reason": null}`, "sentiment", "score", "reason", "topics")
+	if !errors.Is(err, ErrMissingKeys) || err.Error() != "missing keys: score, topics" {
+		t.Errorf("Testing ParseJSONInto with missing keys: want ErrMissingKeys listing score and topics, got %v", err)
+	}
+	if want := map[string]string{"sentiment": "negative", "reason": "null"}; !maps.Equal(ev.Data, want) {
+		t.Errorf("Testing ParseJSONInto with missing keys: want %v, got %v", want, ev.Data)
+	}
+	ev = NewBaseEvent("classify", map[string]string{"text": "I love Go"})
+	for _, malformed := range []string{`{"sentiment": "positive"`, `["positive"]`, "null", "Sure! Here is the JSON you asked for."} {
+		if err := ParseJSONInto(ev, malformed); err == nil {
+			t.Errorf("Testing ParseJSONInto with %q: expected an error", malformed)
+		}
+	}
+	if len(ev.Data) != 1 {
+		t.Errorf("Testing ParseJSONInto with malformed JSON: want the event unchanged, got %v", ev.Data)
+	}
+}
@@ -0,0 +1,68 @@
+package workflowsgo
+
+import (
+	"context"
+	"sync"
+)
+
+// PauseController pauses and resumes the runs started with the
+// WithPauseController option, e.g. to apply backpressure when downstream
+// consumers are slow. Runs only pause at step boundaries: a step that is
+// executing when Pause is called runs to completion, and the run waits
+// before executing the next one. It is safe for concurrent use.
+type PauseController struct {
+	mu      sync.Mutex
+	resumed chan struct{}
+}
+
+// NewPauseController is a constructor that returns a PauseController that
+// is not paused.
+func NewPauseController() *PauseController {
+	return &PauseController{}
+}
+
+// Pause makes the controlled runs wait before their next step, until
+// Resume is called. Pausing a paused controller has no effect.
+func (pc *PauseController) Pause() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.resumed == nil {
+		pc.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets the controlled runs go on. Resuming a controller that is not
+// paused has no effect.
+func (pc *PauseController) Resume() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if pc.resumed != nil {
+		close(pc.resumed)
+		pc.resumed = nil
+	}
+}
+
+// Paused reports whether the controller is paused.
+func (pc *PauseController) Paused() bool {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.resumed != nil
+}
+
+// wait blocks while the controller is paused, or until the context.Context
+// is cancelled. A nil controller never blocks.
+func (pc *PauseController) wait(ctx context.Context) {
+	if pc == nil {
+		return
+	}
+	pc.mu.Lock()
+	resumed := pc.resumed
+	pc.mu.Unlock()
+	if resumed == nil {
+		return
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
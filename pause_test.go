@@ -0,0 +1,40 @@
+package workflowsgo
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPauseController(t *testing.T) {
+	pc := NewPauseController()
+	var executed atomic.Int32
+	count := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if executed.Add(1) == 3 {
+			pc.Pause()
+		}
+		if executed.Load() == 5 {
+			return NewBaseEvent("end", map[string]string{"output": "done"})
+		}
+		return NewBaseEvent("count", map[string]string{})
+	}
+	wf := NewBaseWorkflow("count", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"count": count})
+	done := make(chan any, 1)
+	go wf.RunWith(context.Background(), NewBaseEvent("count", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), WithPauseController(pc), WithOnOutput(func(out any) {
+		done <- out
+	}))
+	time.Sleep(20 * time.Millisecond)
+	if n := executed.Load(); n != 3 || !pc.Paused() {
+		t.Fatalf("Testing PauseController.Pause: want the run paused after 3 steps, got %d steps", n)
+	}
+	pc.Resume()
+	select {
+	case out := <-done:
+		if out != "done" || executed.Load() != 5 {
+			t.Errorf("Testing PauseController.Resume: want 'done' after 5 steps, got %v after %d steps", out, executed.Load())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Testing PauseController.Resume: the run did not complete")
+	}
+}
@@ -0,0 +1,72 @@
+package workflowsgo
+
+import (
+	"container/heap"
+	"context"
+)
+
+// PriorityQueue is a queue of events that dequeues the event with the
+// highest Priority first; events with the same Priority are dequeued in
+// the order in which they were pushed. The zero value is an empty queue. It
+// is not safe for concurrent use.
+type PriorityQueue struct {
+	items priorityHeap
+	seq   int
+}
+
+// Push adds an event to the queue.
+func (q *PriorityQueue) Push(ev *BaseEvent) {
+	heap.Push(&q.items, queuedEvent{ev: ev, seq: q.seq})
+	q.seq++
+}
+
+// Pop removes and returns the event with the highest Priority, or nil if
+// the queue is empty.
+func (q *PriorityQueue) Pop() *BaseEvent {
+	if q.items.Len() == 0 {
+		return nil
+	}
+	return heap.Pop(&q.items).(queuedEvent).ev
+}
+
+// Len returns the number of events in the queue.
+func (q *PriorityQueue) Len() int {
+	return q.items.Len()
+}
+
+// queuedEvent is an event in a PriorityQueue, with the sequence number
+// used to break ties.
+type queuedEvent struct {
+	ev  *BaseEvent
+	seq int
+}
+
+// priorityHeap implements heap.Interface for PriorityQueue.
+type priorityHeap []queuedEvent
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].ev.Priority != h[j].ev.Priority {
+		return h[i].ev.Priority > h[j].ev.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityHeap) Push(x any) { *h = append(*h, x.(queuedEvent)) }
+
+func (h *priorityHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// RunWithPriority runs the workflow like RunWithEventBus, but processes the
+// returned and emitted events in order of Priority, from the highest, using
+// a PriorityQueue instead of FIFO order.
+func (wf *BaseWorkflow) RunWithPriority(ctx context.Context, inputEvent *BaseEvent, wfCtx *BaseContext, onEventStartCallBack func(*BaseEvent), onEventEndCallBack func(*BaseEvent), onOutputCallBack func(any)) {
+	wf.runQueued(ctx, inputEvent, wfCtx, &PriorityQueue{}, onEventStartCallBack, onEventEndCallBack, onOutputCallBack)
+}
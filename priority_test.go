@@ -0,0 +1,47 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := &PriorityQueue{}
+	for i, priority := range []int{1, 5, 1, 3} {
+		ev := NewBaseEvent("step", map[string]string{"id": string(rune('a' + i))})
+		ev.Priority = priority
+		q.Push(ev)
+	}
+	ids := []string{}
+	for q.Len() > 0 {
+		ids = append(ids, q.Pop().Data["id"])
+	}
+	if !slices.Equal(ids, []string{"b", "d", "a", "c"}) {
+		t.Errorf("Testing PriorityQueue: want %v, got %v", []string{"b", "d", "a", "c"}, ids)
+	}
+	if q.Pop() != nil {
+		t.Error("Testing PriorityQueue.Pop on an empty queue: expected nil")
+	}
+}
+
+func TestRunWithPriority(t *testing.T) {
+	steps := map[string]StepFunc{
+		"fanOut": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			for _, priority := range []int{0, 10, 5} {
+				ev := NewBaseEvent("end", map[string]string{"output": string(rune('0' + priority/5))})
+				ev.Priority = priority
+				EmitEvent(ctx, ev)
+			}
+			return nil
+		},
+	}
+	wf := NewBaseWorkflow("fanOut", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	outputs := []any{}
+	wf.RunWithPriority(context.Background(), NewBaseEvent("fanOut", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}), func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	})
+	if !slices.Equal(outputs, []any{"2", "1", "0"}) {
+		t.Errorf("Testing BaseWorkflow.RunWithPriority: want %v, got %v", []any{"2", "1", "0"}, outputs)
+	}
+}
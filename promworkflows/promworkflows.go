@@ -0,0 +1,116 @@
+// promworkflows integrates workflows-go with Prometheus metrics.
+//
+// It lives in its own package so that the core workflowsgo package does not
+// depend on the Prometheus client: importing promworkflows is the opt-in.
+package promworkflows
+
+import (
+	"errors"
+	"time"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the collectors updated by the workflows configured with
+// WithPrometheus.
+type Metrics struct {
+	// StepsTotal counts the executed steps, labeled by step name.
+	StepsTotal *prometheus.CounterVec
+	// StepDuration observes the duration of the executed steps in seconds,
+	// labeled by step name.
+	StepDuration *prometheus.HistogramVec
+	// RunsTotal counts the ended runs, labeled by status, either
+	// "completed" or "failed".
+	RunsTotal *prometheus.CounterVec
+}
+
+// NewMetrics is a constructor that returns the Metrics registered with
+// registry. Collectors that are already registered, e.g. by another
+// workflow sharing the registry, are reused.
+func NewMetrics(registry prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		StepsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workflow_steps_total",
+			Help: "Number of workflow steps executed.",
+		}, []string{"step"}),
+		StepDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "workflow_step_duration_seconds",
+			Help:    "Duration of the executed workflow steps.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"step"}),
+		RunsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "workflow_runs_total",
+			Help: "Number of workflow runs ended, by status.",
+		}, []string{"status"}),
+	}
+	var err error
+	if m.StepsTotal, err = register(registry, m.StepsTotal); err != nil {
+		return nil, err
+	}
+	if m.StepDuration, err = register(registry, m.StepDuration); err != nil {
+		return nil, err
+	}
+	if m.RunsTotal, err = register(registry, m.RunsTotal); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register registers c with registry, returning the collector that is
+// already registered in its place, if any.
+func register[C prometheus.Collector](registry prometheus.Registerer, c C) (C, error) {
+	err := registry.Register(c)
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		if existing, ok := already.ExistingCollector.(C); ok {
+			return existing, nil
+		}
+	}
+	return c, err
+}
+
+// ObserveStep counts and times an executed step, as reported to
+// BaseWorkflow.OnStepTiming.
+func (m *Metrics) ObserveStep(stepName string, d time.Duration) {
+	m.StepsTotal.WithLabelValues(stepName).Inc()
+	m.StepDuration.WithLabelValues(stepName).Observe(d.Seconds())
+}
+
+// ObserveRun counts an ended run, as reported to BaseWorkflow.OnRunEnd.
+func (m *Metrics) ObserveRun(output any, err error) {
+	if err != nil {
+		m.RunsTotal.WithLabelValues("failed").Inc()
+		return
+	}
+	m.RunsTotal.WithLabelValues("completed").Inc()
+}
+
+// WithPrometheus is a workflowsgo.WorkflowOption that records the metrics
+// of the workflow in registry: the steps it executes, their durations and
+// its completed and failed runs. It keeps any OnStepTiming and OnRunEnd
+// already set, and panics if the metrics cannot be registered, e.g.
+// because collectors with the same names but different labels are already
+// registered.
+func WithPrometheus(registry *prometheus.Registry) workflowsgo.WorkflowOption {
+	return func(wf *workflowsgo.BaseWorkflow) {
+		m, err := NewMetrics(registry)
+		if err != nil {
+			panic(err)
+		}
+		onStepTiming := wf.OnStepTiming
+		wf.OnStepTiming = func(stepName string, d time.Duration) {
+			m.ObserveStep(stepName, d)
+			if onStepTiming != nil {
+				onStepTiming(stepName, d)
+			}
+		}
+		onRunEnd := wf.OnRunEnd
+		wf.OnRunEnd = func(output any, err error) {
+			m.ObserveRun(output, err)
+			if onRunEnd != nil {
+				onRunEnd(output, err)
+			}
+		}
+	}
+}
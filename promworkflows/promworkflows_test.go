@@ -0,0 +1,51 @@
+package promworkflows
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestWithPrometheus(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	steps := map[string]workflowsgo.StepFunc{
+		"retrieve": func(ctx context.Context, ev *workflowsgo.BaseEvent, wfCtx *workflowsgo.BaseContext) *workflowsgo.BaseEvent {
+			return workflowsgo.NewBaseEvent("generate", map[string]string{})
+		},
+		"generate": func(ctx context.Context, ev *workflowsgo.BaseEvent, wfCtx *workflowsgo.BaseContext) *workflowsgo.BaseEvent {
+			return workflowsgo.NewBaseEvent("end", map[string]string{"output": "hello world"})
+		},
+	}
+	wf := workflowsgo.NewBaseWorkflow("retrieve", workflowsgo.NewBaseContext(map[string]any{}, map[string]any{}), steps, WithPrometheus(registry))
+	if _, err := wf.RunSync(context.Background(), workflowsgo.NewBaseEvent("retrieve", map[string]string{}), workflowsgo.NewBaseContext(map[string]any{}, map[string]any{})); err != nil {
+		t.Fatalf("Testing WithPrometheus: unexpected error %v", err)
+	}
+	other := workflowsgo.NewBaseWorkflow("missing", workflowsgo.NewBaseContext(map[string]any{}, map[string]any{}), steps, WithPrometheus(registry))
+	other.RunSync(context.Background(), workflowsgo.NewBaseEvent("missing", map[string]string{}), workflowsgo.NewBaseContext(map[string]any{}, map[string]any{}))
+
+	server := httptest.NewServer(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Testing WithPrometheus: could not scrape the metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`workflow_steps_total{step="retrieve"} 1`,
+		`workflow_steps_total{step="generate"} 1`,
+		`workflow_step_duration_seconds_count{step="retrieve"} 1`,
+		`workflow_runs_total{status="completed"} 1`,
+		`workflow_runs_total{status="failed"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Testing WithPrometheus: want the metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// EventPublisher publishes events to an external message queue, e.g. a
+// NATS subject or a Kafka topic, for distributed processing. The package
+// stays transport-agnostic: implementations own the serialization and the
+// connection, see InMemoryPublisher.
+type EventPublisher interface {
+	Publish(topic string, ev *BaseEvent) error
+}
+
+// PublishStep returns a step that hands the incoming event over to pub,
+// under topic, instead of processing it in-process, and routes to 'end'
+// with "published to <topic>" as output. If pub fails, the step routes to
+// 'end' with the error under ErrorKey and as output.
+func PublishStep(pub EventPublisher, topic string) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if err := pub.Publish(topic, ev.Clone()); err != nil {
+			message := fmt.Sprintf("cannot publish the event to %s: %v", topic, err)
+			return NewBaseEvent("end", map[string]string{
+				ErrorKey: message,
+				"output": message,
+			})
+		}
+		return NewBaseEvent("end", map[string]string{"output": "published to " + topic})
+	}
+}
+
+// InMemoryPublisher is an implementation of EventPublisher that keeps the
+// published events in memory, serialized as JSON like a real transport
+// would, e.g. for tests. It is safe for concurrent use.
+type InMemoryPublisher struct {
+	mu       sync.Mutex
+	messages map[string][][]byte
+}
+
+// NewInMemoryPublisher is a constructor that returns an empty
+// InMemoryPublisher.
+func NewInMemoryPublisher() *InMemoryPublisher {
+	return &InMemoryPublisher{messages: map[string][][]byte{}}
+}
+
+// Publish serializes ev as JSON and appends it to the messages of topic.
+func (p *InMemoryPublisher) Publish(topic string, ev *BaseEvent) error {
+	message, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages[topic] = append(p.messages[topic], message)
+	return nil
+}
+
+// Published returns the events published under topic, in order, decoded
+// from their JSON messages.
+func (p *InMemoryPublisher) Published(topic string) ([]*BaseEvent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := make([]*BaseEvent, 0, len(p.messages[topic]))
+	for _, message := range p.messages[topic] {
+		var ev BaseEvent
+		if err := json.Unmarshal(message, &ev); err != nil {
+			return nil, err
+		}
+		events = append(events, &ev)
+	}
+	return events, nil
+}
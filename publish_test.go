@@ -0,0 +1,36 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(string, *BaseEvent) error {
+	return errors.New("broker unavailable")
+}
+
+func TestPublishStep(t *testing.T) {
+	pub := NewInMemoryPublisher()
+	steps := map[string]StepFunc{
+		"prepare": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("publish", map[string]string{"order": ev.Data["order"], "status": "ready"})
+		},
+		"publish": PublishStep(pub, "orders"),
+	}
+	wf := NewBaseWorkflow("prepare", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("prepare", map[string]string{"order": "A1"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "published to orders" {
+		t.Errorf("Testing PublishStep: want 'published to orders' and no error, got %v and %v", out, err)
+	}
+	published, err := pub.Published("orders")
+	if err != nil || len(published) != 1 || published[0].Data["order"] != "A1" || published[0].Data["status"] != "ready" || published[0].SourceStep != "prepare" {
+		t.Errorf("Testing InMemoryPublisher: want the prepared event published once, got %v and %v", published, err)
+	}
+	wf.Steps["publish"] = PublishStep(failingPublisher{}, "orders")
+	if out, _ := wf.RunSync(context.Background(), NewBaseEvent("prepare", map[string]string{"order": "A2"}), NewBaseContext(map[string]any{}, map[string]any{})); out != "cannot publish the event to orders: broker unavailable" {
+		t.Errorf("Testing PublishStep with a failing publisher: want the error as output, got %v", out)
+	}
+}
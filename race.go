@@ -0,0 +1,39 @@
+package workflowsgo
+
+import "context"
+
+// Race runs alternative workflows concurrently on the same input event,
+// e.g. a fast heuristic against a slow model, and returns the output of the
+// first one to complete successfully. Each workflow runs in its own
+// goroutine with its own clones of ev and wfCtx (or an empty context, if
+// wfCtx is nil). Once a winner is found, the context.Context of the other runs is
+// cancelled, so that they stop before their next step: Race returns
+// without waiting for them, and whatever they did before stopping, such as
+// calls to external services, is not undone.
+//
+// If every workflow fails, the output is the error of the last one to
+// fail, as passed to the output callback of Run. Racing no workflows
+// returns nil.
+func Race(ctx context.Context, wfs []*BaseWorkflow, ev *BaseEvent, wfCtx *BaseContext) any {
+	if wfCtx == nil {
+		wfCtx = NewBaseContext(map[string]any{}, map[string]any{})
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	outputs := make(chan any, len(wfs))
+	for _, wf := range wfs {
+		go func(wf *BaseWorkflow, runEv *BaseEvent, runCtx *BaseContext) {
+			var output any
+			wf.RunWith(ctx, runEv, runCtx, WithOnOutput(func(out any) { output = out }))
+			outputs <- output
+		}(wf, ev.Clone(), wfCtx.Clone())
+	}
+	var output any
+	for range wfs {
+		output = <-outputs
+		if _, failed := output.(error); !failed {
+			return output
+		}
+	}
+	return output
+}
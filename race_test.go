@@ -0,0 +1,57 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRace(t *testing.T) {
+	answer := func(text string, delay time.Duration) *BaseWorkflow {
+		think := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+			return NewBaseEvent("answer", map[string]string{})
+		}
+		respond := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": text})
+		}
+		return NewBaseWorkflow("think", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"think": think, "answer": respond})
+	}
+	slow, fast := answer("slow model", time.Second), answer("fast heuristic", 0)
+	start := time.Now()
+	if out := Race(context.Background(), []*BaseWorkflow{slow, fast}, NewBaseEvent("think", map[string]string{}), nil); out != "fast heuristic" {
+		t.Errorf("Testing Race: want the fast output to win, got %v", out)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Testing Race: want Race to return without waiting for the slow workflow, took %v", elapsed)
+	}
+	broken := NewBaseWorkflow("missing", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{})
+	if out := Race(context.Background(), []*BaseWorkflow{broken, answer("fallback", 10*time.Millisecond)}, NewBaseEvent("missing", map[string]string{}), nil); out != "fallback" {
+		t.Errorf("Testing Race with a failing workflow: want the successful output, got %v", out)
+	}
+	if err, ok := Race(context.Background(), []*BaseWorkflow{broken}, NewBaseEvent("missing", map[string]string{}), nil).(error); !ok || !errors.Is(err, ErrStepNotFound) {
+		t.Errorf("Testing Race when every workflow fails: want the error, got %v", err)
+	}
+}
+
+func TestRaceClonesEvent(t *testing.T) {
+	annotate := func(name string) *BaseWorkflow {
+		tag := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			ev.Data["racer"] = name
+			return NewBaseEvent("end", map[string]string{"output": ev.Data["racer"]})
+		}
+		return NewBaseWorkflow("tag", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"tag": tag})
+	}
+	input := NewBaseEvent("tag", map[string]string{"query": "hi"})
+	out := Race(context.Background(), []*BaseWorkflow{annotate("first"), annotate("second"), annotate("third")}, input, nil)
+	if out != "first" && out != "second" && out != "third" {
+		t.Errorf("Testing Race with steps writing to their input: want the name of a racer, got %v", out)
+	}
+	if _, ok := input.Data["racer"]; ok || len(input.Data) != 1 {
+		t.Errorf("Testing Race with steps writing to their input: want the input event unchanged, got %v", input.Data)
+	}
+}
@@ -0,0 +1,56 @@
+package workflowsgo
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// WithSeed is a ContextOption that seeds the source of randomness returned
+// by ContextRand, so that the steps sampling from it behave the same way in
+// every run, e.g. under test.
+func WithSeed(seed int64) ContextOption {
+	return func(ctx *BaseContext) {
+		ctx.rng = newLockedRand(seed)
+	}
+}
+
+// ContextRand returns the source of randomness of the BaseContext, that
+// stochastic steps should use instead of the global functions of math/rand
+// to be reproducible. It is seeded with WithSeed, or from the current time
+// if no seed was given, and it is safe for concurrent use.
+func (ctx *BaseContext) ContextRand() *rand.Rand {
+	defer ctx.lock()()
+	if ctx.rng == nil {
+		ctx.rng = newLockedRand(time.Now().UnixNano())
+	}
+	return ctx.rng
+}
+
+// lockedSource is a rand.Source64 that is safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func newLockedRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
@@ -0,0 +1,27 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestContextRand(t *testing.T) {
+	sample := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("end", map[string]string{"output": fmt.Sprint(wfCtx.ContextRand().Intn(1000), wfCtx.ContextRand().Float64())})
+	}
+	wf := NewBaseWorkflow("sample", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"sample": sample})
+	run := func(seed int64) any {
+		out, err := wf.RunSync(context.Background(), NewBaseEvent("sample", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}, WithSeed(seed)))
+		if err != nil {
+			t.Fatalf("Testing ContextRand: unexpected error %v", err)
+		}
+		return out
+	}
+	if first, second := run(42), run(42); first != second {
+		t.Errorf("Testing ContextRand with the same seed: want identical outputs, got %v and %v", first, second)
+	}
+	if first, other := run(42), run(7); first == other {
+		t.Errorf("Testing ContextRand with different seeds: want different outputs, got %v twice", first)
+	}
+}
@@ -0,0 +1,35 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+)
+
+// Limiter paces the steps wrapped with RateLimit. A *rate.Limiter from
+// golang.org/x/time/rate satisfies it, as does any limiter whose Wait blocks
+// until the next call is allowed or the context.Context is done.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateLimit wraps a step so that every invocation first waits for limiter,
+// e.g. to stay within the requests per minute allowed by an external API.
+// The limiter can be shared by several steps, or workflows, calling the
+// same API.
+//
+// The wait honors the context.Context of the run: if it is cancelled while
+// waiting, or if limiter reports that the wait would exceed its deadline,
+// the step is not invoked and the workflow moves on with an event routing
+// to 'end', carrying the error under ErrorKey and as output.
+func RateLimit(step StepFunc, limiter Limiter) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if err := limiter.Wait(ctx); err != nil {
+			message := fmt.Sprintf("rate limited step stopped before invocation: %v", err)
+			return NewBaseEvent("end", map[string]string{
+				ErrorKey: message,
+				"output": message,
+			})
+		}
+		return step(ctx, ev, wfCtx)
+	}
+}
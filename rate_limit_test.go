@@ -0,0 +1,45 @@
+package workflowsgo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimit(t *testing.T) {
+	calls := 0
+	step := RateLimit(func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		calls++
+		return NewBaseEvent("end", map[string]string{"output": "completion"})
+	}, rate.NewLimiter(rate.Every(50*time.Millisecond), 1))
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if event := step(context.Background(), NewBaseEvent("complete", map[string]string{}), wfCtx); event.Data["output"] != "completion" {
+			t.Fatalf("Testing RateLimit: want the event of the step, got %v", event.Data)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Testing RateLimit: want 4 invocations to take about 150ms, took %s", elapsed)
+	}
+
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	limiter.Allow()
+	limited := RateLimit(func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		calls++
+		return NewBaseEvent("end", map[string]string{"output": "completion"})
+	}, limiter)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiring, cancelExpiring := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelExpiring()
+	for _, ctx := range []context.Context{cancelled, expiring} {
+		event := limited(ctx, NewBaseEvent("complete", map[string]string{}), wfCtx)
+		if event.NextStep != "end" || !strings.HasPrefix(event.Data[ErrorKey], "rate limited step stopped before invocation") || calls != 4 {
+			t.Errorf("Testing RateLimit with a cancelled wait: want an error event routing to end without invoking the step, got %v after %d calls", event, calls)
+		}
+	}
+}
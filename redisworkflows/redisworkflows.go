@@ -0,0 +1,128 @@
+// redisworkflows provides a Redis-backed implementation of
+// workflowsgo.GenericContext, so that the context of a workflow can be
+// shared by processes running on different machines.
+package redisworkflows
+
+import (
+	"context"
+	"sync"
+
+	"github.com/AstraBert/workflows-go/internal/gzipjson"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisContext is an implementation of workflowsgo.GenericContext that keeps
+// its Store and State in Redis, under keys starting with Prefix. Values are
+// JSON-encoded, so they are read back as the types produced by
+// encoding/json (e.g. structs as map[string]any and numbers as float64).
+//
+// Since the methods of GenericContext do not return errors, failed Redis
+// operations are reported as missing values by GetValue and GetState, and
+// the last error is available through Err.
+//
+// CompressThreshold, when greater than 0, makes the values whose JSON
+// encoding is longer than that many bytes, e.g. long documents, be stored
+// gzip-compressed. The gzip header flags compressed values, which cannot be
+// mistaken for JSON, so that they are decompressed transparently on read,
+// whatever the threshold, and can coexist with uncompressed ones.
+type RedisContext struct {
+	Client            redis.Cmdable
+	Prefix            string
+	CompressThreshold int
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewRedisContext is a constructor that, given a Redis client and a key prefix, returns a RedisContext.
+func NewRedisContext(client redis.Cmdable, prefix string) *RedisContext {
+	return &RedisContext{
+		Client: client,
+		Prefix: prefix,
+	}
+}
+
+// storeKey returns the Redis key holding a value of the Store.
+func (rc *RedisContext) storeKey(key string) string {
+	return rc.Prefix + "store:" + key
+}
+
+// stateKey returns the Redis key holding the State.
+func (rc *RedisContext) stateKey() string {
+	return rc.Prefix + "state"
+}
+
+// setErr records the error of the last failed operation.
+func (rc *RedisContext) setErr(err error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.err = err
+}
+
+// Err returns the error of the last failed operation, if any. A missing key
+// is not considered an error.
+func (rc *RedisContext) Err() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.err
+}
+
+// set JSON-encodes a value, compressing it if it is longer than
+// CompressThreshold, and stores it under a Redis key.
+func (rc *RedisContext) set(key string, val any) {
+	b, err := gzipjson.Encode(val, rc.CompressThreshold)
+	if err != nil {
+		rc.setErr(err)
+		return
+	}
+	if err := rc.Client.Set(context.Background(), key, b, 0).Err(); err != nil {
+		rc.setErr(err)
+	}
+}
+
+// get fetches a Redis key, decompressing it if needed, and JSON-decodes it
+// into dst, reporting whether it succeeded.
+func (rc *RedisContext) get(key string, dst any) bool {
+	b, err := rc.Client.Get(context.Background(), key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			rc.setErr(err)
+		}
+		return false
+	}
+	if err := gzipjson.Decode(b, dst); err != nil {
+		rc.setErr(err)
+		return false
+	}
+	return true
+}
+
+// StoreValue stores a key-value pair in the Redis-backed Store.
+func (rc *RedisContext) StoreValue(key string, val any) {
+	rc.set(rc.storeKey(key), val)
+}
+
+// GetValue fetches the value associated with a key in the Redis-backed
+// Store, returning nil and false if the key is missing or cannot be read.
+func (rc *RedisContext) GetValue(key string) (any, bool) {
+	var val any
+	if !rc.get(rc.storeKey(key), &val) {
+		return nil, false
+	}
+	return val, true
+}
+
+// GetState fetches the Redis-backed State, returning an empty map if it was
+// never set or cannot be read.
+func (rc *RedisContext) GetState() map[string]any {
+	state := map[string]any{}
+	if !rc.get(rc.stateKey(), &state) || state == nil {
+		return map[string]any{}
+	}
+	return state
+}
+
+// SetState assigns a value to the Redis-backed State.
+func (rc *RedisContext) SetState(state map[string]any) {
+	rc.set(rc.stateKey(), state)
+}
@@ -0,0 +1,73 @@
+package redisworkflows
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisContext(t *testing.T) {
+	server := miniredis.RunT(t)
+	worker1 := NewRedisContext(redis.NewClient(&redis.Options{Addr: server.Addr()}), "run-1:")
+	worker2 := NewRedisContext(redis.NewClient(&redis.Options{Addr: server.Addr()}), "run-1:")
+	other := NewRedisContext(redis.NewClient(&redis.Options{Addr: server.Addr()}), "run-2:")
+	var _ workflowsgo.GenericContext = worker1
+
+	worker1.StoreValue("user", map[string]any{"name": "John Doe", "age": 30})
+	user, ok := worker2.GetValue("user")
+	if !ok {
+		t.Fatal("Testing RedisContext.GetValue: expected the value stored by another client to be found")
+	}
+	if !maps.Equal(user.(map[string]any), map[string]any{"name": "John Doe", "age": float64(30)}) {
+		t.Errorf("Testing RedisContext.GetValue: unexpected value %v", user)
+	}
+	if _, ok := other.GetValue("user"); ok {
+		t.Error("Testing RedisContext.GetValue with another prefix: expected the value not to be found")
+	}
+	if val, ok := worker2.GetValue("missing"); ok || val != nil || worker2.Err() != nil {
+		t.Errorf("Testing RedisContext.GetValue with a missing key: want nil, false and no error, got %v, %v and %v", val, ok, worker2.Err())
+	}
+
+	if state := worker1.GetState(); len(state) != 0 {
+		t.Errorf("Testing RedisContext.GetState before SetState: want an empty state, got %v", state)
+	}
+	worker1.SetState(map[string]any{"iterations": 3, "success": true})
+	if state := worker2.GetState(); !maps.Equal(state, map[string]any{"iterations": float64(3), "success": true}) {
+		t.Errorf("Testing RedisContext.SetState and GetState: unexpected state %v", state)
+	}
+
+	server.Close()
+	if val, ok := worker1.GetValue("user"); ok || val != nil {
+		t.Errorf("Testing RedisContext.GetValue with a closed connection: want nil and false, got %v and %v", val, ok)
+	}
+	if worker1.Err() == nil {
+		t.Error("Testing RedisContext.Err with a closed connection: expected the error to be recorded")
+	}
+}
+
+func TestRedisContextCompression(t *testing.T) {
+	server := miniredis.RunT(t)
+	writer := NewRedisContext(redis.NewClient(&redis.Options{Addr: server.Addr()}), "run-1:")
+	writer.CompressThreshold = 1024
+	reader := NewRedisContext(redis.NewClient(&redis.Options{Addr: server.Addr()}), "run-1:")
+	document := strings.Repeat("a long document ", 1<<16)
+	writer.StoreValue("document", document)
+	writer.StoreValue("title", "short")
+	stored, err := server.Get("run-1:store:document")
+	if err != nil || len(stored) >= len(document)/10 {
+		t.Errorf("Testing RedisContext.CompressThreshold: want the stored value much smaller than the %d bytes document, got %d bytes and %v", len(document), len(stored), err)
+	}
+	if title, _ := server.Get("run-1:store:title"); title != `"short"` {
+		t.Errorf("Testing RedisContext.CompressThreshold: want short values stored uncompressed, got %q", title)
+	}
+	if val, _ := reader.GetValue("document"); val != document {
+		t.Error("Testing RedisContext.GetValue with a compressed value: the document did not round-trip")
+	}
+	if val, _ := reader.GetValue("title"); val != "short" || reader.Err() != nil {
+		t.Errorf("Testing RedisContext.GetValue with mixed values: want 'short' and no error, got %v and %v", val, reader.Err())
+	}
+}
@@ -0,0 +1,93 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Predicate is a condition evaluated against the incoming event and the
+// workflow context, used by the routing helpers to pick the next step.
+type Predicate func(*BaseEvent, *BaseContext) bool
+
+// Branch returns a step that routes the incoming event, with its Data
+// unchanged, to the first step whose condition is satisfied. Conditions are
+// evaluated in ascending alphabetical order of their step names, so that
+// routing is deterministic; if none is satisfied, the event is routed to
+// defaultStep.
+func Branch(conditions map[string]Predicate, defaultStep string) StepFunc {
+	targets := sortedKeys(conditions)
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		for _, target := range targets {
+			if conditions[target](ev, wfCtx) {
+				return NewBaseEvent(target, maps.Clone(ev.Data))
+			}
+		}
+		return NewBaseEvent(defaultStep, maps.Clone(ev.Data))
+	}
+}
+
+// BranchRule routes to Target the events satisfying Predicate, see
+// PriorityBranch.
+type BranchRule struct {
+	Priority  int
+	Predicate Predicate
+	Target    string
+}
+
+// PriorityBranch returns a step that routes the incoming event, with its
+// Data unchanged, to the Target of the first rule whose Predicate is
+// satisfied. Rules are evaluated in ascending order of Priority, ties being
+// broken by the order of rules; if none is satisfied, the event is routed
+// to defaultStep.
+func PriorityBranch(rules []BranchRule, defaultStep string) StepFunc {
+	ordered := slices.Clone(rules)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority < ordered[j].Priority })
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		for _, rule := range ordered {
+			if rule.Predicate(ev, wfCtx) {
+				return NewBaseEvent(rule.Target, maps.Clone(ev.Data))
+			}
+		}
+		return NewBaseEvent(defaultStep, maps.Clone(ev.Data))
+	}
+}
+
+// WeightedRoute returns a step that routes the incoming event, with its
+// Data unchanged, to one of the steps of weights, picked at random with a
+// probability proportional to its weight: weights of 9 and 1, or of 0.9
+// and 0.1, route 90% of the events to the first step. Steps are drawn with
+// the ContextRand of the workflow context, so that a context built with
+// WithSeed makes the routing reproducible.
+//
+// An error is returned if a weight is negative or not finite, or if the
+// weights do not sum to more than zero. Steps with a zero weight are never
+// picked.
+func WeightedRoute(weights map[string]float64) (StepFunc, error) {
+	var targets []string
+	var cumulative []float64
+	total := 0.0
+	for _, target := range sortedKeys(weights) {
+		weight := weights[target]
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid weight %v for step %s", weight, target)
+		}
+		if weight == 0 {
+			continue
+		}
+		total += weight
+		targets = append(targets, target)
+		cumulative = append(cumulative, total)
+	}
+	if !(total > 0) || math.IsInf(total, 0) {
+		return nil, fmt.Errorf("the weights must sum to a positive finite number, got %v", total)
+	}
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		draw := wfCtx.ContextRand().Float64() * total
+		i := sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > draw })
+		return NewBaseEvent(targets[min(i, len(targets)-1)], maps.Clone(ev.Data))
+	}, nil
+}
@@ -0,0 +1,97 @@
+package workflowsgo
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestBranch(t *testing.T) {
+	step := Branch(map[string]Predicate{
+		"escalate": func(ev *BaseEvent, wfCtx *BaseContext) bool {
+			return wfCtx.GetState()["priority"] == "high"
+		},
+		"answer": func(ev *BaseEvent, wfCtx *BaseContext) bool {
+			_, ok := ev.Get("query")
+			return ok
+		},
+	}, "fallback")
+	var tests = []struct {
+		data  map[string]string
+		state map[string]any
+		want  string
+	}{
+		{map[string]string{"query": "hi"}, map[string]any{"priority": "high"}, "answer"},
+		{map[string]string{}, map[string]any{"priority": "high"}, "escalate"},
+		{map[string]string{"query": "hi"}, map[string]any{}, "answer"},
+		{map[string]string{}, map[string]any{}, "fallback"},
+	}
+	for _, tt := range tests {
+		event := step(context.Background(), NewBaseEvent("branch", tt.data), NewBaseContext(map[string]any{}, tt.state))
+		if event.NextStep != tt.want {
+			t.Errorf("Testing Branch with data %v and state %v: want %s, got %s", tt.data, tt.state, tt.want, event.NextStep)
+		}
+	}
+}
+
+func TestPriorityBranch(t *testing.T) {
+	always := func(*BaseEvent, *BaseContext) bool { return true }
+	hasQuery := func(ev *BaseEvent, wfCtx *BaseContext) bool {
+		_, ok := ev.Get("query")
+		return ok
+	}
+	step := PriorityBranch([]BranchRule{
+		{Priority: 2, Predicate: always, Target: "generic"},
+		{Priority: 1, Predicate: hasQuery, Target: "answer"},
+		{Priority: 1, Predicate: always, Target: "tie"},
+	}, "fallback")
+	var tests = []struct {
+		data map[string]string
+		want string
+	}{
+		{map[string]string{"query": "hi"}, "answer"},
+		{map[string]string{}, "tie"},
+	}
+	for _, tt := range tests {
+		event := step(context.Background(), NewBaseEvent("branch", tt.data), NewBaseContext(map[string]any{}, map[string]any{}))
+		if event.NextStep != tt.want {
+			t.Errorf("Testing PriorityBranch: want %s, got %s", tt.want, event.NextStep)
+		}
+	}
+	event := PriorityBranch(nil, "fallback")(context.Background(), NewBaseEvent("branch", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if event.NextStep != "fallback" {
+		t.Errorf("Testing PriorityBranch without rules: want fallback, got %s", event.NextStep)
+	}
+}
+
+func TestWeightedRoute(t *testing.T) {
+	step, err := WeightedRoute(map[string]float64{"variantA": 9, "variantB": 1, "retired": 0})
+	if err != nil {
+		t.Fatalf("Testing WeightedRoute: unexpected error %v", err)
+	}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{}, WithSeed(42))
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		event := step(context.Background(), NewBaseEvent("experiment", map[string]string{"query": "hi"}), wfCtx)
+		if event.Data["query"] != "hi" {
+			t.Fatalf("Testing WeightedRoute: want the Data unchanged, got %v", event.Data)
+		}
+		counts[event.NextStep]++
+	}
+	if counts["variantA"] < 8800 || counts["variantA"] > 9200 || counts["variantA"]+counts["variantB"] != 10000 {
+		t.Errorf("Testing WeightedRoute: want about 9000 events routed to variantA and the rest to variantB, got %v", counts)
+	}
+	first, again := NewBaseContext(map[string]any{}, map[string]any{}, WithSeed(7)), NewBaseContext(map[string]any{}, map[string]any{}, WithSeed(7))
+	for i := 0; i < 100; i++ {
+		a := step(context.Background(), NewBaseEvent("experiment", map[string]string{}), first).NextStep
+		b := step(context.Background(), NewBaseEvent("experiment", map[string]string{}), again).NextStep
+		if a != b {
+			t.Fatalf("Testing WeightedRoute with the same seed: want the same routing, got %s and %s at draw %d", a, b, i)
+		}
+	}
+	for _, weights := range []map[string]float64{{"variantA": -1, "variantB": 2}, {"variantA": 0}, {}, {"variantA": math.NaN()}} {
+		if _, err := WeightedRoute(weights); err == nil {
+			t.Errorf("Testing WeightedRoute with weights %v: expected an error", weights)
+		}
+	}
+}
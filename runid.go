@@ -0,0 +1,31 @@
+package workflowsgo
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// RunIDKey is the key of BaseContext.State under which Run records the
+// unique identifier of the run, see BaseContext.RunID.
+const RunIDKey = "__runID__"
+
+// RunID returns the unique identifier of the last run that used this
+// context, or an empty string if it was not used by any run. The identifier
+// is a random UUID generated when the run starts, unless one was supplied
+// with WithRunID, and it is kept by RunFrom, which continues the run.
+func (ctx *BaseContext) RunID() string {
+	defer ctx.rlock()()
+	id, _ := ctx.State[RunIDKey].(string)
+	return id
+}
+
+// newRunID generates a random (version 4) UUID.
+func newRunID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("cannot generate run ID: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
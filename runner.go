@@ -0,0 +1,79 @@
+package workflowsgo
+
+import (
+	"context"
+	"sync"
+)
+
+// Runner runs a workflow on behalf of a long-lived service, e.g. behind an
+// HTTP server, keeping track of the runs in flight so that Shutdown can
+// drain them. It is safe for concurrent use.
+type Runner struct {
+	Workflow *BaseWorkflow
+
+	mu       sync.Mutex
+	closed   bool
+	stop     chan struct{}
+	inFlight sync.WaitGroup
+}
+
+// NewRunner is a constructor that returns a Runner for wf.
+func NewRunner(wf *BaseWorkflow) *Runner {
+	return &Runner{Workflow: wf, stop: make(chan struct{})}
+}
+
+// Run runs the workflow through completion like RunSync, with the given
+// options. Once Shutdown is called, the run stops before its next step with
+// an error wrapping ErrRunnerShutdown, and new runs are refused with the
+// same error.
+func (r *Runner) Run(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext, opts ...RunOption) (any, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, ErrRunnerShutdown
+	}
+	r.inFlight.Add(1)
+	r.mu.Unlock()
+	defer r.inFlight.Done()
+	var res runResult
+	opts = append(opts, res.option(), func(o *RunOptions) { o.stop = r.stop })
+	r.Workflow.RunWith(ctx, ev, wfCtx, opts...)
+	return res.get()
+}
+
+// Shutdown stops the Runner from accepting new runs and signals the runs in
+// flight to stop once their current step completes, without interrupting
+// it. It returns when every run has stopped, or with the error of ctx if it
+// is done first. Calling Shutdown again waits for the same runs.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.stop)
+	}
+	r.mu.Unlock()
+	drained := make(chan struct{})
+	go func() {
+		r.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopping reports whether the Runner that started the run is shutting down.
+func (opts *RunOptions) stopping() bool {
+	if opts.stop == nil {
+		return false
+	}
+	select {
+	case <-opts.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package workflowsgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunnerShutdown(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	executed := []string{}
+	steps := map[string]StepFunc{
+		"first": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			close(started)
+			<-release
+			executed = append(executed, "first")
+			return NewBaseEvent("second", map[string]string{})
+		},
+		"second": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			executed = append(executed, "second")
+			return NewBaseEvent("end", map[string]string{"output": "done"})
+		},
+	}
+	runner := NewRunner(NewBaseWorkflow("first", NewBaseContext(map[string]any{}, map[string]any{}), steps))
+	done := make(chan error, 1)
+	go func() {
+		_, err := runner.Run(context.Background(), NewBaseEvent("first", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+		done <- err
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := runner.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Testing Runner.Shutdown with a step in flight: want the deadline to expire, got %v", err)
+	}
+	close(release)
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Errorf("Testing Runner.Shutdown: want the runs to drain, got %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrRunnerShutdown) || len(executed) != 1 || executed[0] != "first" {
+		t.Errorf("Testing Runner.Shutdown: want the current step to complete and the run to stop with ErrRunnerShutdown, got %v after %v", err, executed)
+	}
+	if _, err := runner.Run(context.Background(), NewBaseEvent("first", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{})); !errors.Is(err, ErrRunnerShutdown) {
+		t.Errorf("Testing Runner.Run after Shutdown: want ErrRunnerShutdown, got %v", err)
+	}
+}
+
+func TestRunnerRunWithCallbackBuffer(t *testing.T) {
+	steps := map[string]StepFunc{
+		"greet": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "hi"})
+		},
+	}
+	runner := NewRunner(NewBaseWorkflow("greet", NewBaseContext(map[string]any{}, map[string]any{}), steps))
+	buf := NewCallbackBuffer(1)
+	defer buf.Close()
+	var recorded any
+	out, err := runner.Run(context.Background(), NewBaseEvent("greet", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}),
+		WithCallbackBuffer(buf), WithOnOutput(func(out any) {
+			time.Sleep(5 * time.Millisecond)
+			recorded = out
+		}))
+	if err != nil || out != "hi" {
+		t.Errorf("Testing Runner.Run with a CallbackBuffer: want 'hi' and no error, got %v and %v", out, err)
+	}
+	buf.Flush()
+	if recorded != "hi" {
+		t.Errorf("Testing Runner.Run with a CallbackBuffer: want the buffered OnOutput to receive 'hi', got %v", recorded)
+	}
+}
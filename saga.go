@@ -0,0 +1,94 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// RegisterCompensation registers the compensating action of the step named
+// stepName, replacing any previous one. When a run fails, the compensations
+// of the steps it already completed are invoked in reverse order of
+// completion, each with the event emitted by its step, as in the saga
+// pattern.
+//
+// A run fails when a step emits an event carrying a value under ErrorKey
+// (including the events built from recovered panics), or when it stops
+// with an error, e.g. because of a missing step or MaxSteps; a step is
+// completed when it emits an event that does not carry ErrorKey. The
+// compensated steps are forgotten, so that a run continuing after a
+// failure, e.g. with ErrorStep, only compensates the steps completed since.
+// A compensation that panics is logged to Logger, if set, and the remaining
+// compensations still run.
+func (wf *BaseWorkflow) RegisterCompensation(stepName string, fn StepHook) {
+	if wf.compensations == nil {
+		wf.compensations = map[string]StepHook{}
+	}
+	wf.compensations[stepName] = fn
+}
+
+// completedStep is a step completed within a run, with the event it
+// emitted.
+type completedStep struct {
+	name  string
+	event *BaseEvent
+}
+
+// saga tracks the steps completed within a run that have a compensation.
+type saga struct {
+	wf        *BaseWorkflow
+	wfCtx     *BaseContext
+	mu        sync.Mutex
+	completed []completedStep
+}
+
+// newSaga returns the saga of a run using wfCtx, hooking its compensations
+// to the failures reported through opts.
+func (wf *BaseWorkflow) newSaga(wfCtx *BaseContext, opts *RunOptions) *saga {
+	s := &saga{wf: wf, wfCtx: wfCtx}
+	opts.compensate = s.compensate
+	return s
+}
+
+// resolve executes a step with runStep, recording its completion or
+// compensating the completed steps if it fails.
+func (s *saga) resolve(ctx context.Context, stepName string, stepIndex int, ev *BaseEvent, wfCtx *BaseContext) (*BaseEvent, error) {
+	event, err := s.wf.runStep(ctx, stepName, stepIndex, ev, wfCtx)
+	switch {
+	case err != nil && event == nil:
+		// The run stops, and RunOptions.fail compensates.
+	case isFailed(event):
+		s.compensate()
+	default:
+		if _, ok := s.wf.compensations[stepName]; ok {
+			s.mu.Lock()
+			s.completed = append(s.completed, completedStep{name: stepName, event: event.Clone()})
+			s.mu.Unlock()
+		}
+	}
+	return event, err
+}
+
+// compensate invokes the compensations of the completed steps, the latest
+// first, and forgets them.
+func (s *saga) compensate() {
+	s.mu.Lock()
+	completed := s.completed
+	s.completed = nil
+	s.mu.Unlock()
+	for i := len(completed) - 1; i >= 0; i-- {
+		s.invoke(completed[i])
+	}
+}
+
+// invoke runs the compensation of a completed step, recovering and logging
+// its panics.
+func (s *saga) invoke(step completedStep) {
+	defer func() {
+		if r := recover(); r != nil && s.wf.Logger != nil {
+			s.wf.Logger.LogAttrs(context.Background(), slog.LevelError, "compensation failed", slog.String("runID", s.wfCtx.RunID()), slog.String("step", step.name), slog.String("error", fmt.Sprint(r)))
+		}
+	}()
+	s.wf.compensations[step.name](step.event, s.wfCtx)
+}
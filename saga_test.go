@@ -0,0 +1,51 @@
+package workflowsgo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowCompensation(t *testing.T) {
+	step := func(next string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent(next, map[string]string{})
+		}
+	}
+	steps := map[string]StepFunc{
+		"reserve": step("charge"),
+		"charge":  step("notify"),
+		"notify":  step("ship"),
+		"ship": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{ErrorKey: "out of stock", "output": "failed"})
+		},
+	}
+	var buf bytes.Buffer
+	wf := NewBaseWorkflow("reserve", NewBaseContext(map[string]any{}, map[string]any{}), steps, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	compensated := []string{}
+	wf.RegisterCompensation("reserve", func(ev *BaseEvent, wfCtx *BaseContext) { compensated = append(compensated, "release") })
+	wf.RegisterCompensation("charge", func(ev *BaseEvent, wfCtx *BaseContext) { compensated = append(compensated, "refund") })
+	wf.RegisterCompensation("notify", func(ev *BaseEvent, wfCtx *BaseContext) { panic("mail server down") })
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("reserve", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "failed" {
+		t.Fatalf("Testing BaseWorkflow.RegisterCompensation: want 'failed' and no error, got %v and %v", out, err)
+	}
+	if !slices.Equal(compensated, []string{"refund", "release"}) {
+		t.Errorf("Testing BaseWorkflow.RegisterCompensation: want %v, got %v", []string{"refund", "release"}, compensated)
+	}
+	if !strings.Contains(buf.String(), "compensation failed") {
+		t.Errorf("Testing BaseWorkflow.RegisterCompensation: want the failed compensation to be logged, got %s", buf.String())
+	}
+
+	compensated = []string{}
+	steps["ship"] = step("missing")
+	if _, err := wf.RunSync(context.Background(), NewBaseEvent("reserve", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{})); err == nil {
+		t.Fatal("Testing BaseWorkflow.RegisterCompensation with a missing step: expected an error")
+	}
+	if !slices.Equal(compensated, []string{"refund", "release"}) {
+		t.Errorf("Testing BaseWorkflow.RegisterCompensation with a missing step: want %v, got %v", []string{"refund", "release"}, compensated)
+	}
+}
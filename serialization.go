@@ -0,0 +1,50 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MarshalJSON encodes a BaseEvent as a JSON object with the nextStep and
+// data keys, plus the priority, createdAt, sourceStep, idempotencyKey and
+// nextSteps keys when they are set. A nil Data map is encoded as an empty
+// object.
+func (ev BaseEvent) MarshalJSON() ([]byte, error) {
+	type plainEvent BaseEvent
+	plain := struct {
+		plainEvent
+		CreatedAt *time.Time `json:"createdAt,omitempty"`
+	}{plainEvent: plainEvent(ev)}
+	if plain.Data == nil {
+		plain.Data = map[string]string{}
+	}
+	if !ev.CreatedAt.IsZero() {
+		plain.CreatedAt = &ev.CreatedAt
+	}
+	return json.Marshal(plain)
+}
+
+// UnmarshalJSON decodes a BaseEvent from a JSON object with the nextStep
+// and data keys. A missing or null data key is decoded as an empty Data
+// map, so that decoded events never have a nil Data.
+func (ev *BaseEvent) UnmarshalJSON(b []byte) error {
+	type plainEvent BaseEvent
+	var plain plainEvent
+	if err := json.Unmarshal(b, &plain); err != nil {
+		return err
+	}
+	if plain.Data == nil {
+		plain.Data = map[string]string{}
+	}
+	*ev = BaseEvent(plain)
+	return nil
+}
+
+// EventFromJSON is a constructor function that decodes a BaseEvent from its JSON representation.
+func EventFromJSON(b []byte) (*BaseEvent, error) {
+	ev := &BaseEvent{}
+	if err := json.Unmarshal(b, ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
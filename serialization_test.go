@@ -0,0 +1,43 @@
+package workflowsgo
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+)
+
+func TestEventJSON(t *testing.T) {
+	event := NewBaseEvent("summarize", map[string]string{"query": "what is Go?", "lang": "en"})
+	b, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Testing BaseEvent.MarshalJSON: unexpected error %v", err)
+	}
+	decoded, err := EventFromJSON(b)
+	if err != nil {
+		t.Fatalf("Testing EventFromJSON: unexpected error %v", err)
+	}
+	if decoded.NextStep != event.NextStep || !maps.Equal(decoded.Data, event.Data) {
+		t.Errorf("Testing EventFromJSON: want %v, got %v", event, decoded)
+	}
+	if val, _ := decoded.Get("query"); val != "what is Go?" {
+		t.Errorf("Testing EventFromJSON and BaseEvent.Get: Expected 'what is Go?', gotten %v", val)
+	}
+
+	for _, ev := range []*BaseEvent{NewBaseEvent("end", nil), NewBaseEvent("end", map[string]string{})} {
+		b, err := json.Marshal(ev)
+		if err != nil {
+			t.Fatalf("Testing BaseEvent.MarshalJSON with empty data: unexpected error %v", err)
+		}
+		if string(b) != `{"nextStep":"end","data":{}}` {
+			t.Errorf("Testing BaseEvent.MarshalJSON with empty data: got %s", b)
+		}
+		decoded, err := EventFromJSON(b)
+		if err != nil || decoded.Data == nil || len(decoded.Data) != 0 {
+			t.Errorf("Testing EventFromJSON with empty data: want an empty non-nil map, got %v (error: %v)", decoded, err)
+		}
+	}
+
+	if _, err := EventFromJSON([]byte(`{"nextStep": 1}`)); err == nil {
+		t.Error("Testing EventFromJSON with malformed JSON: expected an error")
+	}
+}
@@ -0,0 +1,104 @@
+package workflowsgo
+
+import (
+	"maps"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// contextSnapshot is the state of a BaseContext captured by Snapshot.
+type contextSnapshot struct {
+	token       string
+	store       map[string]any
+	state       map[string]any
+	expirations map[string]time.Time
+}
+
+// Snapshot captures the Store and State of the BaseContext, returning a
+// token that Rollback restores them with. Maps, slices and arrays held by
+// them are copied recursively, so that later changes to the context do not
+// alter the snapshot; any other value, including pointers, is shared.
+//
+// Snapshots are nested: a rollback restores the given snapshot and
+// discards it together with every snapshot taken after it, in LIFO order.
+func (ctx *BaseContext) Snapshot() string {
+	defer ctx.lock()()
+	ctx.snapshotSeq++
+	token := "snapshot-" + strconv.Itoa(ctx.snapshotSeq)
+	ctx.snapshots = append(ctx.snapshots, contextSnapshot{
+		token:       token,
+		store:       deepCopy(ctx.Store),
+		state:       deepCopy(ctx.State),
+		expirations: maps.Clone(ctx.expirations),
+	})
+	return token
+}
+
+// Rollback restores the Store and State captured by the Snapshot that
+// returned token, discarding that snapshot and the ones taken after it.
+// Unknown tokens, including the ones of discarded snapshots, are ignored.
+func (ctx *BaseContext) Rollback(token string) {
+	defer ctx.lock()()
+	for i := len(ctx.snapshots) - 1; i >= 0; i-- {
+		snapshot := ctx.snapshots[i]
+		if snapshot.token != token {
+			continue
+		}
+		ctx.Store = snapshot.store
+		ctx.State = snapshot.state
+		ctx.expirations = snapshot.expirations
+		ctx.snapshots = ctx.snapshots[:i]
+		return
+	}
+}
+
+// deepCopy copies m, recursively copying the maps, slices and arrays it
+// holds.
+func deepCopy(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(m)).Interface().(map[string]any)
+}
+
+// copyValue returns a copy of v, recursing into maps, slices, arrays and
+// interfaces; any other value is returned unchanged.
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cp.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(copyValue(v.Elem()))
+		return cp
+	default:
+		return v
+	}
+}
@@ -0,0 +1,34 @@
+package workflowsgo
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestContextSnapshotRollback(t *testing.T) {
+	ctx := NewBaseContext(map[string]any{"documents": []string{"a"}}, map[string]any{"status": "idle"})
+	outer := ctx.Snapshot()
+	ctx.Store["documents"].([]string)[0] = "changed"
+	ctx.StoreValue("answer", "42")
+	ctx.SetState(map[string]any{"status": "running"})
+	inner := ctx.Snapshot()
+	ctx.StoreValue("answer", "43")
+
+	ctx.Rollback(inner)
+	if val, _ := ctx.GetValue("answer"); val != "42" {
+		t.Errorf("Testing BaseContext.Rollback to the inner snapshot: want 42, got %v", val)
+	}
+	ctx.Rollback(outer)
+	if val, _ := ctx.GetValue("documents"); !slices.Equal(val.([]string), []string{"a"}) {
+		t.Errorf("Testing BaseContext.Rollback: want the original documents, got %v", val)
+	}
+	if _, ok := ctx.GetValue("answer"); ok || ctx.GetState()["status"] != "idle" {
+		t.Errorf("Testing BaseContext.Rollback: want the original store and state, got %v and %v", ctx.Store, ctx.GetState())
+	}
+
+	ctx.StoreValue("answer", "44")
+	ctx.Rollback(inner)
+	if val, _ := ctx.GetValue("answer"); val != "44" {
+		t.Errorf("Testing BaseContext.Rollback with a discarded snapshot: want no change, got %v", val)
+	}
+}
@@ -0,0 +1,137 @@
+// sqliteworkflows provides a SQLite-backed implementation of
+// workflowsgo.GenericContext, so that the context of workflow runs can be
+// persisted and queried with SQL.
+//
+// The package only relies on database/sql: callers open the *sql.DB with
+// the SQLite driver of their choice.
+package sqliteworkflows
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TableName is the name of the table holding the values of all runs.
+const TableName = "workflow_context"
+
+// stateKey is the key of the row holding the State of a run.
+const stateKey = "state"
+
+// SQLiteContext is an implementation of workflowsgo.GenericContext that keeps
+// its Store and State in a SQLite table, with one row per key holding the
+// JSON-encoded value and the time of the last update. Rows are scoped by
+// RunID, so that several runs can share the same table. Values go through
+// JSON, so they are read back as the types produced by encoding/json (e.g.
+// structs as map[string]any and numbers as float64).
+//
+// Since the methods of GenericContext do not return errors, failed queries
+// are reported as missing values by GetValue and GetState, and the last
+// error is available through Err.
+type SQLiteContext struct {
+	DB    *sql.DB
+	RunID string
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewSQLiteContext is a constructor that returns a SQLiteContext storing the
+// values of the given run in db, creating the table if it does not exist.
+func NewSQLiteContext(db *sql.DB, runID string) (*SQLiteContext, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + TableName + ` (
+		run_id TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (run_id, scope, key)
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the %s table: %w", TableName, err)
+	}
+	return &SQLiteContext{
+		DB:    db,
+		RunID: runID,
+	}, nil
+}
+
+// setErr records the error of the last failed operation.
+func (sc *SQLiteContext) setErr(err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.err = err
+}
+
+// Err returns the error of the last failed operation, if any. A missing key
+// is not considered an error.
+func (sc *SQLiteContext) Err() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.err
+}
+
+// set JSON-encodes a value and upserts it.
+func (sc *SQLiteContext) set(scope, key string, val any) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		sc.setErr(err)
+		return
+	}
+	_, err = sc.DB.Exec(`INSERT INTO `+TableName+` (run_id, scope, key, value, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (run_id, scope, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		sc.RunID, scope, key, string(b), time.Now().UTC())
+	if err != nil {
+		sc.setErr(err)
+	}
+}
+
+// get fetches a value and JSON-decodes it into dst, reporting whether it
+// succeeded.
+func (sc *SQLiteContext) get(scope, key string, dst any) bool {
+	var value string
+	err := sc.DB.QueryRow(`SELECT value FROM `+TableName+` WHERE run_id = ? AND scope = ? AND key = ?`, sc.RunID, scope, key).Scan(&value)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			sc.setErr(err)
+		}
+		return false
+	}
+	if err := json.Unmarshal([]byte(value), dst); err != nil {
+		sc.setErr(err)
+		return false
+	}
+	return true
+}
+
+// StoreValue stores a key-value pair in the Store of the run.
+func (sc *SQLiteContext) StoreValue(key string, val any) {
+	sc.set("store", key, val)
+}
+
+// GetValue fetches the value associated with a key in the Store of the
+// run, returning nil and false if the key is missing or cannot be read.
+func (sc *SQLiteContext) GetValue(key string) (any, bool) {
+	var val any
+	if !sc.get("store", key, &val) {
+		return nil, false
+	}
+	return val, true
+}
+
+// GetState fetches the State of the run, returning an empty map if it was
+// never set or cannot be read.
+func (sc *SQLiteContext) GetState() map[string]any {
+	state := map[string]any{}
+	if !sc.get("state", stateKey, &state) || state == nil {
+		return map[string]any{}
+	}
+	return state
+}
+
+// SetState assigns a value to the State of the run.
+func (sc *SQLiteContext) SetState(state map[string]any) {
+	sc.set("state", stateKey, state)
+}
@@ -0,0 +1,59 @@
+package sqliteworkflows
+
+import (
+	"database/sql"
+	"maps"
+	"path/filepath"
+	"testing"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLiteContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflows.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run1, err := NewSQLiteContext(db, "run-1")
+	if err != nil {
+		t.Fatalf("Testing NewSQLiteContext: unexpected error %v", err)
+	}
+	run2, err := NewSQLiteContext(db, "run-2")
+	if err != nil {
+		t.Fatalf("Testing NewSQLiteContext: unexpected error %v", err)
+	}
+	var _ workflowsgo.GenericContext = run1
+	run1.StoreValue("answer", "hello")
+	run1.StoreValue("answer", "hello world")
+	run1.SetState(map[string]any{"iterations": 2})
+	run2.StoreValue("answer", "ciao")
+	if err := run1.Err(); err != nil {
+		t.Fatalf("Testing SQLiteContext.StoreValue: unexpected error %v", err)
+	}
+	db.Close()
+
+	db, err = sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	reopened, err := NewSQLiteContext(db, "run-1")
+	if err != nil {
+		t.Fatalf("Testing NewSQLiteContext after reopening: unexpected error %v", err)
+	}
+	if answer, ok := reopened.GetValue("answer"); !ok || answer != "hello world" {
+		t.Errorf("Testing SQLiteContext.GetValue after reopening: want 'hello world' and true, got %v and %v", answer, ok)
+	}
+	if state := reopened.GetState(); !maps.Equal(state, map[string]any{"iterations": float64(2)}) {
+		t.Errorf("Testing SQLiteContext.GetState after reopening: unexpected state %v", state)
+	}
+	if val, ok := reopened.GetValue("missing"); ok || val != nil || reopened.Err() != nil {
+		t.Errorf("Testing SQLiteContext.GetValue with a missing key: want nil, false and no error, got %v, %v and %v", val, ok, reopened.Err())
+	}
+	var rows int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + TableName + ` WHERE scope = 'store' AND key = 'answer'`).Scan(&rows); err != nil || rows != 2 {
+		t.Errorf("Testing SQLiteContext: want 2 rows for the two runs, got %d (error: %v)", rows, err)
+	}
+}
@@ -0,0 +1,89 @@
+package workflowsgo
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// RunStats holds aggregate statistics about a run, see RunWithStats.
+type RunStats struct {
+	// TotalSteps is the number of steps executed, 'end' excluded.
+	TotalSteps int
+	// StepCounts is the number of times each step was executed.
+	StepCounts map[string]int
+	// Duration is the time taken by the run, measured with the clock of
+	// the context.
+	Duration time.Duration
+}
+
+// RunWithStats runs the workflow like RunSync, also returning statistics
+// about the run, which are computed from the execution trace of wfCtx.
+func (wf *BaseWorkflow) RunWithStats(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) (any, RunStats, error) {
+	start := wfCtx.Clock().Now()
+	output, err := wf.RunSync(ctx, ev, wfCtx)
+	stats := RunStats{
+		StepCounts: map[string]int{},
+		Duration:   wfCtx.Clock().Now().Sub(start),
+	}
+	for _, stepName := range wfCtx.ExecutionTrace() {
+		if stepName == wf.terminal() {
+			continue
+		}
+		stats.TotalSteps++
+		stats.StepCounts[stepName]++
+	}
+	return output, stats, err
+}
+
+// StepStats accumulates the number of times each step is executed across
+// the runs of a workflow, see BaseWorkflow.Stats. It is safe for concurrent
+// use, e.g. by the runs of RunBatch, and a nil StepStats records nothing.
+type StepStats struct {
+	mu     sync.Mutex
+	visits map[string]int
+}
+
+// NewStepStats is a constructor that returns an empty StepStats.
+func NewStepStats() *StepStats {
+	return &StepStats{visits: map[string]int{}}
+}
+
+// visit records an execution of the step named stepName.
+func (s *StepStats) visit(stepName string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visits[stepName]++
+}
+
+// HeatMap returns a copy of the number of times each step was executed.
+func (s *StepStats) HeatMap() map[string]int {
+	if s == nil {
+		return map[string]int{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.visits)
+}
+
+// Reset clears the recorded visits.
+func (s *StepStats) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.visits = map[string]int{}
+}
+
+// HeatMap returns the number of times each step was executed across all the
+// runs of the workflow, as recorded by Stats, e.g. to highlight the hot
+// paths of the diagram returned by ToMermaid. Steps that were never
+// executed are left out, and so is 'end'.
+func (wf *BaseWorkflow) HeatMap() map[string]int {
+	return wf.Stats.HeatMap()
+}
@@ -0,0 +1,49 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWorkflowRunWithStats(t *testing.T) {
+	retrieve := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("generate", map[string]string{})
+	}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"retrieve": retrieve, "generate": mockStep})
+	out, stats, err := wf.RunWithStats(context.Background(), NewBaseEvent("retrieve", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "hello world" {
+		t.Fatalf("Testing BaseWorkflow.RunWithStats: want 'hello world' and no error, got %v and %v", out, err)
+	}
+	if stats.TotalSteps != 2 || stats.StepCounts["retrieve"] != 1 || stats.StepCounts["generate"] != 1 {
+		t.Errorf("Testing BaseWorkflow.RunWithStats: want 2 steps, each executed once, got %+v", stats)
+	}
+	if stats.Duration < 0 {
+		t.Errorf("Testing BaseWorkflow.RunWithStats: want a non-negative duration, got %v", stats.Duration)
+	}
+}
+
+func TestWorkflowHeatMap(t *testing.T) {
+	route := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if ev.Data["kind"] == "question" {
+			return NewBaseEvent("answer", map[string]string{})
+		}
+		return NewBaseEvent("end", map[string]string{"output": "ignored"})
+	}
+	wf := NewBaseWorkflow("route", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"route": route, "answer": mockStep})
+	events := []*BaseEvent{}
+	for i := 0; i < 10; i++ {
+		kind := "question"
+		if i%5 == 0 {
+			kind = "chatter"
+		}
+		events = append(events, NewBaseEvent("route", map[string]string{"kind": kind}))
+	}
+	wf.RunBatch(context.Background(), events, NewBaseContext(map[string]any{}, map[string]any{}), 4)
+	if heat := wf.HeatMap(); len(heat) != 2 || heat["route"] != 10 || heat["answer"] != 8 {
+		t.Errorf("Testing BaseWorkflow.HeatMap: want 10 visits of 'route' and 8 of 'answer', got %v", heat)
+	}
+	wf.Stats.Reset()
+	if heat := wf.HeatMap(); len(heat) != 0 {
+		t.Errorf("Testing StepStats.Reset: want no visits, got %v", heat)
+	}
+}
@@ -0,0 +1,233 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorKey is the key of BaseEvent.Data that steps use to signal a
+// failure: an event carrying a value under ErrorKey is considered failed
+// by the step wrappers of this package, e.g. WithRetry.
+const ErrorKey = "error"
+
+// isFailed reports whether an event signals a failure through ErrorKey.
+func isFailed(ev *BaseEvent) bool {
+	if ev == nil {
+		return false
+	}
+	_, ok := ev.Data[ErrorKey]
+	return ok
+}
+
+// WithRetry wraps a step so that it is invoked again, up to attempts times
+// in total, as long as the event it returns carries a value under ErrorKey.
+// Between two attempts it waits for backoff, doubling the wait after every
+// failure. If the context.Context is cancelled while waiting, or if all the
+// attempts fail, the last returned event is passed on unchanged. The wait
+// is measured with the Clock of the workflow context.
+func WithRetry(step StepFunc, attempts int, backoff time.Duration) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		result := step(ctx, ev, wfCtx)
+		wait := backoff
+		for i := 1; i < attempts && isFailed(result); i++ {
+			select {
+			case <-ctx.Done():
+				return result
+			case <-wfCtx.Clock().After(wait):
+			}
+			wait *= 2
+			result = step(ctx, ev, wfCtx)
+		}
+		return result
+	}
+}
+
+// WithTimeout wraps a step so that, if it does not return within timeout,
+// the workflow moves on with an event routing to fallbackStep and carrying
+// a timeout error under ErrorKey.
+//
+// The step runs in its own goroutine and receives a context.Context that is
+// cancelled when the timeout expires: steps honoring it stop promptly,
+// while steps ignoring it keep running in the background and their result
+// is discarded. The timeout is measured with the Clock of the workflow
+// context, while cancellation of the parent context.Context ends the wait
+// right away. A panic of step is recovered and reported like a timeout,
+// with an event routing to fallbackStep and carrying the panic under
+// ErrorKey.
+func WithTimeout(step StepFunc, timeout time.Duration, fallbackStep string) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		stepCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		result := make(chan *BaseEvent, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					result <- NewBaseEvent(fallbackStep, map[string]string{
+						ErrorKey: fmt.Sprintf("step panicked: %v", r),
+					})
+				}
+			}()
+			result <- step(stepCtx, ev, wfCtx)
+		}()
+		select {
+		case event := <-result:
+			return event
+		case <-wfCtx.Clock().After(timeout):
+			return NewBaseEvent(fallbackStep, map[string]string{
+				ErrorKey: fmt.Sprintf("step timed out after %s", timeout),
+			})
+		case <-ctx.Done():
+			return NewBaseEvent(fallbackStep, map[string]string{
+				ErrorKey: fmt.Sprintf("step stopped before completion: %v", ctx.Err()),
+			})
+		}
+	}
+}
+
+// Parallel returns a step that runs all the given steps concurrently, each
+// with its own copy of the incoming event, and passes the events they
+// return to merge, in the same order as steps, to produce the event that
+// flows onward.
+//
+// The steps share the workflow context: its methods are safe for concurrent
+// use when it was created with NewBaseContext, but steps should still write
+// distinct keys to avoid overwriting each other's results. A panic of a
+// step is recovered, and merge receives in its place an event carrying the
+// panic under ErrorKey.
+func Parallel(steps []StepFunc, merge func([]*BaseEvent) *BaseEvent) StepFunc {
+	return ParallelLimited(steps, merge, 0)
+}
+
+// ParallelLimited works like Parallel, but runs at most maxConcurrency of
+// the steps at a time, the others waiting for a slot, so that fanning out
+// to many steps does not start as many goroutines at once. The events are
+// still passed to merge in the same order as steps, whatever the order in
+// which the steps complete. A maxConcurrency of 0 or less runs all the
+// steps at once.
+func ParallelLimited(steps []StepFunc, merge func([]*BaseEvent) *BaseEvent, maxConcurrency int) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		results := make([]*BaseEvent, len(steps))
+		var slots chan struct{}
+		if maxConcurrency > 0 {
+			slots = make(chan struct{}, maxConcurrency)
+		}
+		var wg sync.WaitGroup
+		for i, step := range steps {
+			if slots != nil {
+				slots <- struct{}{}
+			}
+			wg.Add(1)
+			go func(i int, step StepFunc) {
+				defer wg.Done()
+				if slots != nil {
+					defer func() { <-slots }()
+				}
+				defer func() {
+					if r := recover(); r != nil {
+						results[i] = NewBaseEvent("", map[string]string{ErrorKey: fmt.Sprintf("parallel step %d panicked: %v", i, r)})
+					}
+				}()
+				results[i] = step(ctx, NewBaseEvent(ev.NextStep, maps.Clone(ev.Data)), wfCtx)
+			}(i, step)
+		}
+		wg.Wait()
+		return merge(results)
+	}
+}
+
+// ParentNextStepKey is the key of the final event of a sub-workflow that
+// names the step of the parent workflow to route to, see AsStep.
+const ParentNextStepKey = "parentNextStep"
+
+// AsStep wraps a whole workflow so that it can be registered as a single
+// step of a parent workflow: when the step is invoked, the sub-workflow
+// runs to completion with the incoming event as input.
+//
+// The sub-workflow runs with its own Context (or with an empty one, if it
+// has none), so that it does not interfere with the trace and the state of
+// the parent. The Data of its final event flows to the parent, routing to
+// the step named under ParentNextStepKey, or to 'end' if there is none. If
+// the sub-workflow fails, the step routes to 'end' with the error under
+// ErrorKey and as output.
+func AsStep(wf *BaseWorkflow) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		subCtx := wf.Context
+		if subCtx == nil {
+			subCtx = NewBaseContext(map[string]any{}, map[string]any{})
+		}
+		var last *BaseEvent
+		var output any
+		wf.Run(ctx, ev, subCtx, func(e *BaseEvent) { last = e }, func(*BaseEvent) {}, func(out any) { output = out }, nil)
+		if err, ok := output.(error); ok {
+			return NewBaseEvent("end", map[string]string{
+				ErrorKey: err.Error(),
+				"output": err.Error(),
+			})
+		}
+		data := maps.Clone(last.Data)
+		if data == nil {
+			data = map[string]string{}
+		}
+		nextStep, ok := data[ParentNextStepKey]
+		if !ok {
+			nextStep = "end"
+		}
+		delete(data, ParentNextStepKey)
+		return NewBaseEvent(nextStep, data)
+	}
+}
+
+// RequireKeys wraps a step so that it is only invoked when the incoming
+// event holds all the given keys in its Data. Otherwise, the workflow routes
+// to 'end' with an error naming the missing keys under ErrorKey and as
+// output.
+func RequireKeys(step StepFunc, keys ...string) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		missing := []string{}
+		for _, key := range keys {
+			if _, ok := ev.Data[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			message := fmt.Sprintf("the event is missing the required keys: %s", strings.Join(missing, ", "))
+			return NewBaseEvent("end", map[string]string{
+				ErrorKey: message,
+				"output": message,
+			})
+		}
+		return step(ctx, ev, wfCtx)
+	}
+}
+
+// LoopIterationsKey is the key of the context store under which Loop
+// records the number of iterations performed by its last execution.
+const LoopIterationsKey = "loopIterations"
+
+// Loop returns a step that invokes body repeatedly, passing each time the
+// event returned by the previous iteration, until done is satisfied by the
+// returned event or maxIter iterations have been performed; body is always
+// invoked at least once. The last returned event is then routed, with its
+// Data, to exitStep, and the number of iterations is stored in the context
+// under LoopIterationsKey. If the context.Context is cancelled, the loop
+// stops after the current iteration.
+func Loop(body StepFunc, done Predicate, maxIter int, exitStep string) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		result := body(ctx, ev, wfCtx)
+		iterations := 1
+		for iterations < maxIter && ctx.Err() == nil && !done(result, wfCtx) {
+			result = body(ctx, result, wfCtx)
+			iterations++
+		}
+		wfCtx.StoreValue(LoopIterationsKey, iterations)
+		data := map[string]string{}
+		if result != nil {
+			maps.Copy(data, result.Data)
+		}
+		return NewBaseEvent(exitStep, data)
+	}
+}
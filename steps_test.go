@@ -0,0 +1,229 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	calls := 0
+	flaky := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		calls++
+		if calls < 3 {
+			return NewBaseEvent("end", map[string]string{ErrorKey: "rate limited"})
+		}
+		return NewBaseEvent("end", map[string]string{"output": "hello world"})
+	}
+	event := WithRetry(flaky, 5, time.Millisecond)(context.Background(), NewBaseEvent("flaky", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if calls != 3 {
+		t.Errorf("Testing WithRetry: want 3 calls, got %d", calls)
+	}
+	if val, _ := event.Get("output"); val != "hello world" {
+		t.Errorf("Testing WithRetry: Expected 'hello world', gotten %v", val)
+	}
+
+	calls = 0
+	event = WithRetry(flaky, 2, time.Millisecond)(context.Background(), NewBaseEvent("flaky", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if calls != 2 {
+		t.Errorf("Testing WithRetry with exhausted attempts: want 2 calls, got %d", calls)
+	}
+	if _, ok := event.Get(ErrorKey); !ok {
+		t.Error("Testing WithRetry with exhausted attempts: expected the last failed event to be returned")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	slow := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+		return NewBaseEvent("end", map[string]string{"output": "too late"})
+	}
+	start := time.Now()
+	event := WithTimeout(slow, 20*time.Millisecond, "fallback")(context.Background(), NewBaseEvent("slow", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Testing WithTimeout: the wrapper returned after %s", elapsed)
+	}
+	if event.NextStep != "fallback" {
+		t.Errorf("Testing WithTimeout: Expected 'fallback', gotten %s", event.NextStep)
+	}
+	if _, ok := event.Get(ErrorKey); !ok {
+		t.Error("Testing WithTimeout: expected a timeout error in the event data")
+	}
+	event = WithTimeout(mockStep, time.Second, "fallback")(context.Background(), NewBaseEvent("fast", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if val, _ := event.Get("output"); val != "hello world" || event.NextStep != "end" {
+		t.Errorf("Testing WithTimeout with a fast step: Expected 'hello world' routing to 'end', gotten %v routing to %s", val, event.NextStep)
+	}
+	panicking := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		var counts map[string]int
+		counts["calls"]++
+		return ev
+	}
+	event = WithTimeout(panicking, time.Second, "fallback")(context.Background(), NewBaseEvent("panicking", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if msg := event.Data[ErrorKey]; event.NextStep != "fallback" || !strings.HasPrefix(msg, "step panicked: ") {
+		t.Errorf("Testing WithTimeout with a panicking step: want an error event routing to 'fallback', got %v routing to %s", event.Data, event.NextStep)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	constant := func(n int) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			wfCtx.StoreValue("model"+strconv.Itoa(n), true)
+			return NewBaseEvent("merge", map[string]string{"value": strconv.Itoa(n)})
+		}
+	}
+	sum := func(events []*BaseEvent) *BaseEvent {
+		total := 0
+		for _, ev := range events {
+			n, _ := strconv.Atoi(ev.Data["value"])
+			total += n
+		}
+		return NewBaseEvent("end", map[string]string{"output": strconv.Itoa(total)})
+	}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	event := Parallel([]StepFunc{constant(1), constant(2), constant(3)}, sum)(context.Background(), NewBaseEvent("fanOut", map[string]string{}), wfCtx)
+	if val, _ := event.Get("output"); val != "6" {
+		t.Errorf("Testing Parallel: Expected '6', gotten %v", val)
+	}
+	for _, key := range []string{"model1", "model2", "model3"} {
+		if _, ok := wfCtx.GetValue(key); !ok {
+			t.Errorf("Testing Parallel: expected %s to be stored in the context", key)
+		}
+	}
+}
+
+func TestParallelPanic(t *testing.T) {
+	steps := []StepFunc{
+		mockStep,
+		func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			var counts map[string]int
+			counts["calls"]++
+			return ev
+		},
+	}
+	var merged []*BaseEvent
+	merge := func(results []*BaseEvent) *BaseEvent {
+		merged = results
+		return NewBaseEvent("end", map[string]string{})
+	}
+	Parallel(steps, merge)(context.Background(), NewBaseEvent("fanOut", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if len(merged) != 2 || merged[0].Data["output"] != "hello world" || !strings.HasPrefix(merged[1].Data[ErrorKey], "parallel step 1 panicked: ") {
+		t.Errorf("Testing Parallel with a panicking step: want the event of the first step and an error event for the second, got %v", merged)
+	}
+}
+
+func TestParallelLimited(t *testing.T) {
+	var running, peak atomic.Int32
+	steps := make([]StepFunc, 100)
+	for i := range steps {
+		delay := time.Duration(100-i) * 10 * time.Microsecond
+		steps[i] = func(n int) StepFunc {
+			return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+				now := running.Add(1)
+				for {
+					old := peak.Load()
+					if now <= old || peak.CompareAndSwap(old, now) {
+						break
+					}
+				}
+				time.Sleep(delay)
+				running.Add(-1)
+				return NewBaseEvent("", map[string]string{"n": strconv.Itoa(n)})
+			}
+		}(i)
+	}
+	merge := func(results []*BaseEvent) *BaseEvent {
+		ns := make([]string, len(results))
+		for i, result := range results {
+			ns[i] = result.Data["n"]
+		}
+		return NewBaseEvent("end", map[string]string{"output": strings.Join(ns, ",")})
+	}
+	result := ParallelLimited(steps, merge, 8)(context.Background(), NewBaseEvent("fanOut", map[string]string{}), NewBaseContext(map[string]any{}, map[string]any{}))
+	want := make([]string, 100)
+	for i := range want {
+		want[i] = strconv.Itoa(i)
+	}
+	if result.Data["output"] != strings.Join(want, ",") {
+		t.Errorf("Testing ParallelLimited: want the results in the order of steps, got %s", result.Data["output"])
+	}
+	if p := peak.Load(); p > 8 || p < 1 {
+		t.Errorf("Testing ParallelLimited: want at most 8 steps running at once, got %d", p)
+	}
+}
+
+func TestAsStep(t *testing.T) {
+	summarize := NewBaseWorkflow("summarize", nil, map[string]StepFunc{
+		"summarize": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"summary": "short " + ev.Data["document"], ParentNextStepKey: "answer"})
+		},
+	})
+	rag := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{
+		"retrieve": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("summarize", map[string]string{"document": "doc"})
+		},
+		"summarize": AsStep(summarize),
+		"answer": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			return NewBaseEvent("end", map[string]string{"output": "answer from " + ev.Data["summary"]})
+		},
+	})
+	outputs := []any{}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	rag.Run(context.Background(), NewBaseEvent("retrieve", map[string]string{}), wfCtx, func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) {
+		outputs = append(outputs, out)
+	}, nil)
+	if len(outputs) != 1 || outputs[0] != "answer from short doc" {
+		t.Errorf("Testing AsStep: want %v, got %v", []any{"answer from short doc"}, outputs)
+	}
+	if trace := wfCtx.ExecutionTrace(); !slices.Equal(trace, []string{"retrieve", "summarize", "answer", "end"}) {
+		t.Errorf("Testing AsStep: unexpected parent trace %v", trace)
+	}
+
+	broken := NewBaseWorkflow("missing", nil, map[string]StepFunc{})
+	event := AsStep(broken)(context.Background(), NewBaseEvent("sub", map[string]string{}), wfCtx)
+	if _, ok := event.Get(ErrorKey); !ok || event.NextStep != "end" {
+		t.Errorf("Testing AsStep with a failing sub-workflow: want an error event routing to 'end', got %v", event)
+	}
+}
+
+func TestRequireKeys(t *testing.T) {
+	step := RequireKeys(mockStep, "query", "lang")
+	event := step(context.Background(), NewBaseEvent("answer", map[string]string{"query": "what is Go?", "lang": "en"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if val, _ := event.Get("output"); val != "hello world" {
+		t.Errorf("Testing RequireKeys with all the keys: Expected 'hello world', gotten %v", val)
+	}
+	event = step(context.Background(), NewBaseEvent("answer", map[string]string{"lang": "en"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if msg, ok := event.Data[ErrorKey]; !ok || event.NextStep != "end" || !strings.Contains(msg, "query") || strings.Contains(msg, "lang") {
+		t.Errorf("Testing RequireKeys with a missing key: want an error naming 'query' routing to 'end', got %v", event)
+	}
+}
+
+func TestLoop(t *testing.T) {
+	revise := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		return NewBaseEvent("", map[string]string{"draft": ev.Data["draft"] + "+"})
+	}
+	var tests = []struct {
+		done           Predicate
+		wantIterations int
+		wantDraft      string
+	}{
+		{func(*BaseEvent, *BaseContext) bool { return false }, 3, "+++"},
+		{func(ev *BaseEvent, _ *BaseContext) bool { return ev.Data["draft"] == "++" }, 2, "++"},
+	}
+	for _, tt := range tests {
+		wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+		event := Loop(revise, tt.done, 3, "publish")(context.Background(), NewBaseEvent("refine", map[string]string{"draft": ""}), wfCtx)
+		if event.NextStep != "publish" || event.Data["draft"] != tt.wantDraft {
+			t.Errorf("Testing Loop: want an event routing to publish with draft %q, got %v", tt.wantDraft, event)
+		}
+		if iterations, _ := wfCtx.GetValue(LoopIterationsKey); iterations != tt.wantIterations {
+			t.Errorf("Testing Loop: want %d iterations, got %v", tt.wantIterations, iterations)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package workflowsgo
+
+import "context"
+
+type outputEmitterKey struct{}
+
+// EmitOutput sends a partial output, e.g. a token produced by a model, to
+// the consumer of a run started with RunStream. It blocks until the output
+// is received or the context.Context is cancelled, and returns false if the
+// output was not delivered or if the run was not started with RunStream.
+func EmitOutput(ctx context.Context, out any) bool {
+	emit, ok := ctx.Value(outputEmitterKey{}).(func(any) bool)
+	if !ok {
+		return false
+	}
+	return emit(out)
+}
+
+// RunStream runs the workflow in a new goroutine and returns a channel over
+// which the partial outputs sent by the steps with EmitOutput are delivered,
+// followed by the final output of the workflow (or by the error that
+// stopped it). The channel is closed when the run is over.
+//
+// The consumer should read from the channel until it is closed, or cancel
+// the context.Context to stop the run early.
+func (wf *BaseWorkflow) RunStream(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) <-chan any {
+	outputs := make(chan any)
+	emit := func(out any) bool {
+		select {
+		case outputs <- out:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(outputs)
+		streamCtx := context.WithValue(ctx, outputEmitterKey{}, emit)
+		wf.Run(streamCtx, ev, wfCtx, func(*BaseEvent) {}, func(*BaseEvent) {}, func(out any) { emit(out) }, nil)
+	}()
+	return outputs
+}
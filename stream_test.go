@@ -0,0 +1,39 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunStream(t *testing.T) {
+	steps := map[string]StepFunc{
+		"generate": func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			tokens := strings.Fields(ev.Data["prompt"])
+			for _, token := range tokens {
+				EmitOutput(ctx, token)
+			}
+			return NewBaseEvent("end", map[string]string{"output": strings.Join(tokens, " ")})
+		},
+	}
+	wf := NewBaseWorkflow("generate", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	chunks := []any{}
+	for chunk := range wf.RunStream(context.Background(), NewBaseEvent("generate", map[string]string{"prompt": "hello streaming world"}), NewBaseContext(map[string]any{}, map[string]any{})) {
+		chunks = append(chunks, chunk)
+	}
+	want := []any{"hello", "streaming", "world", "hello streaming world"}
+	if !slices.Equal(chunks, want) {
+		t.Errorf("Testing BaseWorkflow.RunStream: want %v, got %v", want, chunks)
+	}
+	if EmitOutput(context.Background(), "lost") {
+		t.Error("Testing EmitOutput outside of RunStream: expected false")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := wf.RunStream(ctx, NewBaseEvent("generate", map[string]string{"prompt": "a b c d"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	<-stream
+	cancel()
+	for range stream {
+	}
+}
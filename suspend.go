@@ -0,0 +1,92 @@
+package workflowsgo
+
+import (
+	"context"
+	"fmt"
+)
+
+// SuspendStep is the NextStep of the events built by Suspend. When a run
+// reaches such an event it stops, recording in the context the step that
+// will receive the event injected with SuspendableWorkflow.Resume.
+const SuspendStep = "__suspend__"
+
+// Keys of the Data of the events built by Suspend.
+const (
+	SuspendTokenKey = "suspendToken"
+	ResumeStepKey   = "resumeStep"
+)
+
+// suspendedKeyPrefix prefixes the keys of BaseContext.State under which
+// suspended runs are stored, by token.
+const suspendedKeyPrefix = "__suspended__:"
+
+// Suspended is the output of a run that was suspended by a step, see
+// Suspend. Token identifies the run when resuming it.
+type Suspended struct {
+	Token string
+}
+
+// Suspend builds the event a step returns to suspend the run until an
+// external event is injected with SuspendableWorkflow.Resume and the same
+// token. The injected event is then passed to resumeStep.
+func Suspend(token, resumeStep string) *BaseEvent {
+	return NewBaseEvent(SuspendStep, map[string]string{
+		SuspendTokenKey: token,
+		ResumeStepKey:   resumeStep,
+	})
+}
+
+// suspend records the run suspended by ev in the context, returning the
+// output of the run.
+func (ctx *BaseContext) suspend(ev *BaseEvent) Suspended {
+	token := ev.Data[SuspendTokenKey]
+	defer ctx.lock()()
+	if ctx.State == nil {
+		ctx.State = map[string]any{}
+	}
+	ctx.State[suspendedKeyPrefix+token] = ev.Data[ResumeStepKey]
+	return Suspended{Token: token}
+}
+
+// resumeStep returns the step a run suspended with token resumes at,
+// removing the suspension from the context.
+func (ctx *BaseContext) resumeStep(token string) (string, bool) {
+	defer ctx.lock()()
+	step, ok := ctx.State[suspendedKeyPrefix+token].(string)
+	if ok {
+		delete(ctx.State, suspendedKeyPrefix+token)
+	}
+	return step, ok
+}
+
+// SuspendableWorkflow runs a BaseWorkflow in its own Context, so that a run
+// suspended by a step can later be continued with Resume.
+type SuspendableWorkflow struct {
+	*BaseWorkflow
+}
+
+// NewSuspendableWorkflow wraps wf in a SuspendableWorkflow.
+func NewSuspendableWorkflow(wf *BaseWorkflow) *SuspendableWorkflow {
+	return &SuspendableWorkflow{BaseWorkflow: wf}
+}
+
+// Start runs the workflow from FirstStep in its Context, like RunSync. If a
+// step suspends the run, the output is a Suspended value.
+func (wf *SuspendableWorkflow) Start(ctx context.Context, ev *BaseEvent) (any, error) {
+	return wf.RunSync(ctx, ev, wf.Context)
+}
+
+// Resume injects ev into the run suspended with token, passing it to the
+// resume step given to Suspend, and continues the run until it ends or is
+// suspended again. An error is returned if no run is suspended with token.
+func (wf *SuspendableWorkflow) Resume(token string, ev *BaseEvent) (any, error) {
+	step, ok := wf.Context.resumeStep(token)
+	if !ok {
+		return nil, fmt.Errorf("no run suspended with token %s", token)
+	}
+	event := ev.Clone()
+	event.NextStep = step
+	var res runResult
+	wf.runFrom(context.Background(), event, wf.Context, newRunOptions(res.option()))
+	return res.get()
+}
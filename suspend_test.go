@@ -0,0 +1,32 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSuspendableWorkflow(t *testing.T) {
+	requestApproval := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		wfCtx.StoreValue("document", ev.Data["document"])
+		return Suspend("approval-1", "publish")
+	}
+	publish := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if ev.Data["approved"] != "true" {
+			return NewBaseEvent("end", map[string]string{"output": "rejected"})
+		}
+		document, _ := wfCtx.GetValue("document")
+		return NewBaseEvent("end", map[string]string{"output": "published " + document.(string)})
+	}
+	wf := NewSuspendableWorkflow(NewBaseWorkflow("requestApproval", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"requestApproval": requestApproval, "publish": publish}))
+	out, err := wf.Start(context.Background(), NewBaseEvent("requestApproval", map[string]string{"document": "report"}))
+	if err != nil || out != (Suspended{Token: "approval-1"}) {
+		t.Fatalf("Testing SuspendableWorkflow.Start: want a suspended run and no error, got %v and %v", out, err)
+	}
+	out, err = wf.Resume("approval-1", NewBaseEvent("", map[string]string{"approved": "true"}))
+	if err != nil || out != "published report" {
+		t.Errorf("Testing SuspendableWorkflow.Resume: want 'published report' and no error, got %v and %v", out, err)
+	}
+	if _, err := wf.Resume("approval-1", NewBaseEvent("", map[string]string{})); err == nil {
+		t.Error("Testing SuspendableWorkflow.Resume with a used token: expected an error")
+	}
+}
@@ -0,0 +1,46 @@
+package workflowsgo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StepSpan is the time span during which a step was executed, measured
+// with the Clock of the context, around the step and its middlewares.
+type StepSpan struct {
+	StepName string
+	Start    time.Time
+	End      time.Time
+}
+
+// Timeline returns the spans of the steps executed during the last run that
+// used this context, in execution order. Steps that could not be executed,
+// e.g. because they are not registered, have no span.
+func (ctx *BaseContext) Timeline() []StepSpan {
+	defer ctx.rlock()()
+	return slices.Clone(ctx.timeline)
+}
+
+// appendSpan records the span of an executed step in the timeline.
+func (ctx *BaseContext) appendSpan(span StepSpan) {
+	defer ctx.lock()()
+	ctx.timeline = append(ctx.timeline, span)
+}
+
+// ToMermaidGantt renders the Timeline of the context as a Mermaid gantt
+// chart, with a task per executed step, positioned by its start and end
+// times in milliseconds.
+func (ctx *BaseContext) ToMermaidGantt() string {
+	var sb strings.Builder
+	sb.WriteString("gantt\n")
+	sb.WriteString("    dateFormat x\n")
+	sb.WriteString("    axisFormat %H:%M:%S.%L\n")
+	sb.WriteString("    section run\n")
+	for i, span := range ctx.Timeline() {
+		label := strings.ReplaceAll(span.StepName, ":", "#colon;")
+		fmt.Fprintf(&sb, "    %s :step%d, %d, %d\n", label, i, span.Start.UnixMilli(), span.End.UnixMilli())
+	}
+	return sb.String()
+}
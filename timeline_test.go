@@ -0,0 +1,46 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(1000))
+	work := func(d time.Duration, next string) StepFunc {
+		return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+			clock.Advance(d)
+			return NewBaseEvent(next, map[string]string{"output": "done"})
+		}
+	}
+	steps := map[string]StepFunc{"retrieve": work(5*time.Millisecond, "rerank"), "rerank": work(2*time.Millisecond, "generate"), "generate": work(10*time.Millisecond, "end")}
+	wf := NewBaseWorkflow("retrieve", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{}, WithClock(clock))
+	wf.RunSync(context.Background(), NewBaseEvent("retrieve", map[string]string{}), wfCtx)
+	timeline := wfCtx.Timeline()
+	want := []StepSpan{
+		{StepName: "retrieve", Start: time.UnixMilli(1000), End: time.UnixMilli(1005)},
+		{StepName: "rerank", Start: time.UnixMilli(1005), End: time.UnixMilli(1007)},
+		{StepName: "generate", Start: time.UnixMilli(1007), End: time.UnixMilli(1017)},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("Testing BaseContext.Timeline: want %d spans, got %v", len(want), timeline)
+	}
+	for i := range want {
+		if timeline[i].StepName != want[i].StepName || !timeline[i].Start.Equal(want[i].Start) || !timeline[i].End.Equal(want[i].End) {
+			t.Errorf("Testing BaseContext.Timeline: want span %d to be %v, got %v", i, want[i], timeline[i])
+		}
+	}
+	gantt := `gantt
+    dateFormat x
+    axisFormat %H:%M:%S.%L
+    section run
+    retrieve :step0, 1000, 1005
+    rerank :step1, 1005, 1007
+    generate :step2, 1007, 1017
+`
+	if got := wfCtx.ToMermaidGantt(); got != gantt {
+		t.Errorf("Testing BaseContext.ToMermaidGantt: want\n%s\ngot\n%s", gantt, got)
+	}
+}
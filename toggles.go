@@ -0,0 +1,83 @@
+package workflowsgo
+
+import (
+	"sync"
+	"time"
+)
+
+// stepSwitches holds the steps disabled with DisableStep, with their skip
+// targets. It is safe for concurrent use.
+type stepSwitches struct {
+	mu       sync.RWMutex
+	disabled map[string]string
+}
+
+// DisableStep disables the step named name at runtime, without changing
+// the graph of the workflow: until EnableStep is called, every time the
+// step is taken, by Run or TakeStep, it is skipped instead of executed.
+//
+// A skipped step emits a copy of the event it receives, with the same Data
+// and with skipTarget as NextStep. When skipTarget is empty, the NextStep
+// is left empty too, so the event routes to DefaultNextStep or, if it is
+// not set, to 'end'. The skipped step counts as a step of the run: it is
+// recorded in the execution trace and counts towards MaxSteps, but its
+// hooks, middlewares, preprocessors and OnStepTiming are not invoked, and
+// it is neither visited in Stats nor given a span in the timeline.
+//
+// An error is returned if no step is registered under name.
+func (wf *BaseWorkflow) DisableStep(name, skipTarget string) error {
+	if !wf.HasStep(name) {
+		return stepError(name, ErrStepNotFound, "cannot disable step %s, which is not registered", name)
+	}
+	if wf.switches == nil {
+		wf.switches = &stepSwitches{}
+	}
+	wf.switches.mu.Lock()
+	defer wf.switches.mu.Unlock()
+	if wf.switches.disabled == nil {
+		wf.switches.disabled = map[string]string{}
+	}
+	wf.switches.disabled[name] = skipTarget
+	return nil
+}
+
+// EnableStep enables again a step disabled with DisableStep. Enabling a
+// step that is not disabled has no effect.
+func (wf *BaseWorkflow) EnableStep(name string) {
+	if wf.switches == nil {
+		return
+	}
+	wf.switches.mu.Lock()
+	defer wf.switches.mu.Unlock()
+	delete(wf.switches.disabled, name)
+}
+
+// StepEnabled reports whether the step named name is not disabled.
+func (wf *BaseWorkflow) StepEnabled(name string) bool {
+	_, disabled := wf.skipTarget(name)
+	return !disabled
+}
+
+// skipTarget returns the skip target of a disabled step, and whether the
+// step is disabled.
+func (wf *BaseWorkflow) skipTarget(name string) (string, bool) {
+	if wf.switches == nil {
+		return "", false
+	}
+	wf.switches.mu.RLock()
+	defer wf.switches.mu.RUnlock()
+	target, disabled := wf.switches.disabled[name]
+	return target, disabled
+}
+
+// skipEvent returns the event emitted by a skipped step: a copy of ev
+// routed to skipTarget, which is stamped when it is emitted.
+func skipEvent(ev *BaseEvent, skipTarget string) *BaseEvent {
+	event := ev.Clone()
+	if event == nil {
+		event = NewBaseEvent("", map[string]string{})
+	}
+	event.NextStep = skipTarget
+	event.CreatedAt = time.Time{}
+	return event
+}
@@ -0,0 +1,64 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Keys of BaseEvent.Data read and written by the steps built with ToolStep.
+const (
+	// ToolNameKey holds the name of the tool requested by the model.
+	ToolNameKey = "toolName"
+	// ToolArgsKey holds the arguments of the tool call, as a JSON object.
+	ToolArgsKey = "toolArgs"
+	// ToolResultKey holds the result of the tool call: strings are stored
+	// as they are, any other value is encoded as JSON.
+	ToolResultKey = "toolResult"
+)
+
+// ToolStep returns a step that dispatches the tool call carried by the
+// incoming event, as emitted by a function-calling model step, to the
+// matching Go function: the tool name is read under ToolNameKey and its arguments
+// under ToolArgsKey.
+//
+// The emitted event routes back to the step that emitted the call (its
+// SourceStep, or 'end' when there is none), with the tool name and its
+// result under ToolResultKey. If the tool is unknown, the arguments cannot
+// be decoded or the tool returns an error, the event carries the error
+// under ErrorKey instead, so that the model can recover from it.
+func ToolStep(tools map[string]func(args map[string]any) (any, error)) StepFunc {
+	return func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		modelStep := ev.SourceStep
+		if modelStep == "" {
+			modelStep = "end"
+		}
+		name := ev.Data[ToolNameKey]
+		fail := func(err error) *BaseEvent {
+			return NewBaseEvent(modelStep, map[string]string{ToolNameKey: name, ErrorKey: err.Error()})
+		}
+		tool, ok := tools[name]
+		if !ok {
+			return fail(fmt.Errorf("unknown tool %q", name))
+		}
+		args := map[string]any{}
+		if raw := ev.Data[ToolArgsKey]; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &args); err != nil {
+				return fail(fmt.Errorf("invalid arguments for tool %s: %w", name, err))
+			}
+		}
+		result, err := tool(args)
+		if err != nil {
+			return fail(fmt.Errorf("tool %s failed: %w", name, err))
+		}
+		encoded, ok := result.(string)
+		if !ok {
+			raw, err := json.Marshal(result)
+			if err != nil {
+				return fail(fmt.Errorf("cannot encode the result of tool %s: %w", name, err))
+			}
+			encoded = string(raw)
+		}
+		return NewBaseEvent(modelStep, map[string]string{ToolNameKey: name, ToolResultKey: encoded})
+	}
+}
@@ -0,0 +1,34 @@
+package workflowsgo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestToolStep(t *testing.T) {
+	calls := 0
+	tools := map[string]func(args map[string]any) (any, error){
+		"calculator": func(args map[string]any) (any, error) {
+			calls++
+			return args["a"].(float64) + args["b"].(float64), nil
+		},
+	}
+	model := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		if result, ok := ev.Data[ToolResultKey]; ok {
+			return NewBaseEvent("end", map[string]string{"output": "the answer is " + result})
+		}
+		if errMsg, ok := ev.Data[ErrorKey]; ok {
+			return NewBaseEvent("end", map[string]string{"output": errMsg})
+		}
+		return NewBaseEvent("tools", map[string]string{ToolNameKey: ev.Data["tool"], ToolArgsKey: `{"a": 2, "b": 40}`})
+	}
+	wf := NewBaseWorkflow("model", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"model": model, "tools": ToolStep(tools)})
+	out, err := wf.RunSync(context.Background(), NewBaseEvent("model", map[string]string{"tool": "calculator"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if err != nil || out != "the answer is 42" || calls != 1 {
+		t.Errorf("Testing ToolStep: want 'the answer is 42' from a single call, got %v, %v and %d calls", out, err, calls)
+	}
+	out, _ = wf.RunSync(context.Background(), NewBaseEvent("model", map[string]string{"tool": "weather"}), NewBaseContext(map[string]any{}, map[string]any{}))
+	if out != `unknown tool "weather"` {
+		t.Errorf("Testing ToolStep with an unknown tool: want an error routed back to the model, got %v", out)
+	}
+}
@@ -0,0 +1,51 @@
+package workflowsgo
+
+import "slices"
+
+// TraceKey is the key of BaseContext.State under which Run records the names
+// of the steps executed during the run, in order.
+const TraceKey = "__trace__"
+
+// ExecutionTrace returns a copy of the names of the steps executed during
+// the last run that used this context, from the first step to 'end'.
+func (ctx *BaseContext) ExecutionTrace() []string {
+	defer ctx.rlock()()
+	return slices.Clone(traceOf(ctx.State[TraceKey]))
+}
+
+// resetTrace clears the execution trace at the beginning of a run.
+func (ctx *BaseContext) resetTrace() {
+	defer ctx.lock()()
+	if ctx.State == nil {
+		ctx.State = map[string]any{}
+	}
+	ctx.State[TraceKey] = []string{}
+}
+
+// appendTrace records an executed step in the execution trace.
+func (ctx *BaseContext) appendTrace(stepName string) {
+	defer ctx.lock()()
+	if ctx.State == nil {
+		ctx.State = map[string]any{}
+	}
+	ctx.State[TraceKey] = append(traceOf(ctx.State[TraceKey]), stepName)
+}
+
+// traceOf converts the value stored under TraceKey to a slice of step
+// names, also accepting the []any produced when a context is restored from
+// JSON.
+func traceOf(val any) []string {
+	switch trace := val.(type) {
+	case []string:
+		return trace
+	case []any:
+		names := make([]string, 0, len(trace))
+		for _, name := range trace {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
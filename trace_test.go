@@ -0,0 +1,35 @@
+package workflowsgo
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestExecutionTrace(t *testing.T) {
+	steps := map[string]StepFunc{
+		"classify": Branch(map[string]Predicate{
+			"search": func(ev *BaseEvent, wfCtx *BaseContext) bool { return ev.Data["kind"] == "question" },
+		}, "chat"),
+		"search": mockStep,
+		"chat":   mockStep,
+	}
+	wf := NewBaseWorkflow("classify", NewBaseContext(map[string]any{}, map[string]any{}), steps)
+	var tests = []struct {
+		kind string
+		want []string
+	}{
+		{"question", []string{"classify", "search", "end"}},
+		{"greeting", []string{"classify", "chat", "end"}},
+	}
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	for _, tt := range tests {
+		wf.Run(context.Background(), NewBaseEvent("classify", map[string]string{"kind": tt.kind}), wfCtx, func(*BaseEvent) {}, func(*BaseEvent) {}, func(any) {}, nil)
+		if trace := wfCtx.ExecutionTrace(); !slices.Equal(trace, tt.want) {
+			t.Errorf("Testing BaseContext.ExecutionTrace with kind %s: want %v, got %v", tt.kind, tt.want, trace)
+		}
+	}
+	if trace := NewBaseContext(map[string]any{}, nil).ExecutionTrace(); len(trace) != 0 {
+		t.Errorf("Testing BaseContext.ExecutionTrace on a fresh context: want an empty trace, got %v", trace)
+	}
+}
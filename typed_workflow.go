@@ -0,0 +1,79 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// InputKey is the key of BaseEvent.Data under which TypedWorkflow stores
+// a typed input that is not encoded as a JSON object.
+const InputKey = "input"
+
+// TypedWorkflow wraps a BaseWorkflow, giving compile-time types to its
+// input and to its output.
+type TypedWorkflow[In any, Out any] struct {
+	*BaseWorkflow
+}
+
+// NewTypedWorkflow wraps wf in a TypedWorkflow.
+func NewTypedWorkflow[In any, Out any](wf *BaseWorkflow) *TypedWorkflow[In, Out] {
+	return &TypedWorkflow[In, Out]{BaseWorkflow: wf}
+}
+
+// Run runs the workflow like RunSync, with its Context (or with an empty
+// one, if it has none), from an event routing to FirstStep built from in.
+//
+// The input is encoded as JSON: each field of a JSON object is stored in
+// the Data of the event under its name, strings as they are and any other
+// value as JSON, while any other input is stored as JSON under InputKey.
+// The output is returned as is if it is an Out; a string output is
+// otherwise decoded as JSON into an Out. An error is returned if the run
+// fails or if the output cannot be converted to Out.
+func (wf *TypedWorkflow[In, Out]) Run(ctx context.Context, in In) (Out, error) {
+	var zero Out
+	data, err := typedInput(in)
+	if err != nil {
+		return zero, err
+	}
+	wfCtx := wf.Context
+	if wfCtx == nil {
+		wfCtx = NewBaseContext(map[string]any{}, map[string]any{})
+	}
+	output, err := wf.RunSync(ctx, NewBaseEvent(wf.FirstStep, data), wfCtx)
+	if err != nil {
+		return zero, err
+	}
+	if out, ok := output.(Out); ok {
+		return out, nil
+	}
+	if s, ok := output.(string); ok {
+		var out Out
+		if err := json.Unmarshal([]byte(s), &out); err == nil {
+			return out, nil
+		}
+	}
+	return zero, fmt.Errorf("output of type %T is not assignable to %T", output, zero)
+}
+
+// typedInput encodes a typed input as the Data of an event.
+func typedInput(in any) (map[string]string, error) {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode input: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil || fields == nil {
+		return map[string]string{InputKey: string(b)}, nil
+	}
+	data := make(map[string]string, len(fields))
+	for name, raw := range fields {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			data[name] = s
+			continue
+		}
+		data[name] = string(raw)
+	}
+	return data, nil
+}
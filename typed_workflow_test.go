@@ -0,0 +1,34 @@
+package workflowsgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type query struct {
+	Question string `json:"question"`
+	TopK     int    `json:"topK"`
+}
+
+type answer struct {
+	Text    string `json:"text"`
+	Sources int    `json:"sources"`
+}
+
+func TestTypedWorkflow(t *testing.T) {
+	respond := func(ctx context.Context, ev *BaseEvent, wfCtx *BaseContext) *BaseEvent {
+		b, _ := json.Marshal(answer{Text: "answer to " + ev.Data["question"], Sources: len(ev.Data["topK"])})
+		return NewBaseEvent("end", map[string]string{"output": string(b)})
+	}
+	wf := NewTypedWorkflow[query, answer](NewBaseWorkflow("respond", NewBaseContext(map[string]any{}, map[string]any{}), map[string]StepFunc{"respond": respond}))
+	out, err := wf.Run(context.Background(), query{Question: "why?", TopK: 10})
+	if err != nil || out != (answer{Text: "answer to why?", Sources: 2}) {
+		t.Errorf("Testing TypedWorkflow.Run: want a populated answer and no error, got %+v and %v", out, err)
+	}
+
+	number := NewTypedWorkflow[string, int](NewBaseWorkflow("respond", nil, map[string]StepFunc{"respond": mockStep}))
+	if _, err := number.Run(context.Background(), "question"); err == nil {
+		t.Error("Testing TypedWorkflow.Run with an output that is not an Out: expected an error")
+	}
+}
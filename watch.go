@@ -0,0 +1,43 @@
+package workflowsgo
+
+import "reflect"
+
+// Watch registers a callback invoked by StoreValue and StoreValueWithTTL
+// every time they change the value of key, with the previous value, nil if
+// the key was missing or expired, and the new one. Storing a value equal
+// to the current one, as reported by reflect.DeepEqual, does not invoke
+// it. Callbacks run in registration order, after the value is stored and
+// outside of the lock of the context, so they can read and write it.
+//
+// Values changed by SetState, Rollback or by accessing Store directly are
+// not watched, and the callbacks are not copied by Clone.
+func (ctx *BaseContext) Watch(key string, fn func(old, new any)) {
+	defer ctx.lock()()
+	if ctx.watchers == nil {
+		ctx.watchers = map[string][]func(old, new any){}
+	}
+	ctx.watchers[key] = append(ctx.watchers[key], fn)
+}
+
+// current returns the value of key that is not expired, or nil. It must be
+// called while holding a lock.
+func (ctx *BaseContext) current(key string) any {
+	if ctx.expired(key) {
+		return nil
+	}
+	return ctx.Store[key]
+}
+
+// changed invokes the watchers of a key and records the mutation in the
+// change log, if its value changed.
+func (ctx *BaseContext) changed(key string, watchers []func(old, new any), old, val any) {
+	if (len(watchers) == 0 && ctx.changeLog == nil) || reflect.DeepEqual(old, val) {
+		return
+	}
+	if ctx.changeLog != nil {
+		ctx.changeLog.append(ctx.Clock(), "store", key, old, val)
+	}
+	for _, fn := range watchers {
+		fn(old, val)
+	}
+}
@@ -0,0 +1,23 @@
+package workflowsgo
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestContextWatch(t *testing.T) {
+	wfCtx := NewBaseContext(map[string]any{}, map[string]any{})
+	changes := []string{}
+	wfCtx.Watch("status", func(old, new any) {
+		changes = append(changes, fmt.Sprintf("%v->%v", old, new))
+	})
+	wfCtx.StoreValue("status", "pending")
+	wfCtx.StoreValue("status", "pending")
+	wfCtx.StoreValue("other", "ignored")
+	wfCtx.StoreValue("status", "done")
+	want := []string{"<nil>->pending", "pending->done"}
+	if !slices.Equal(changes, want) {
+		t.Errorf("Testing BaseContext.Watch: want %v, got %v", want, changes)
+	}
+}
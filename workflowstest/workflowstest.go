@@ -0,0 +1,132 @@
+// workflowstest provides helpers to compare events and contexts in the
+// tests of workflows built with workflows-go.
+//
+// The comparisons are meant for assertions rather than for production code:
+// they treat as equal the values that a workflow cannot tell apart, such as
+// nil and empty maps, or the same number held as an int and as the float64
+// it becomes after a JSON round-trip, e.g. through a FileContext.
+package workflowstest
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+)
+
+// EventsEqual reports whether two events have the same NextStep, NextSteps
+// and Data, a nil NextSteps or Data being equal to an empty one.
+// SourceStep and CreatedAt, which are stamped when a step emits the event,
+// are not compared. Two nil events are equal, while a nil event differs
+// from any other.
+func EventsEqual(a, b *workflowsgo.BaseEvent) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.NextStep == b.NextStep && slices.Equal(a.NextSteps, b.NextSteps) && maps.Equal(a.Data, b.Data)
+}
+
+// ContextsEqual reports whether two contexts hold equal Store and State
+// maps. Values are compared like reflect.DeepEqual does, except that nil
+// and empty maps and slices are equal, and that numbers are compared by
+// value whatever their type, so that int(1) equals float64(1). Two nil
+// contexts are equal, while a nil context differs from any other.
+func ContextsEqual(a, b *workflowsgo.BaseContext) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	// Clone the contexts to read their maps under their locks.
+	a, b = a.Clone(), b.Clone()
+	return ValuesEqual(a.Store, b.Store) && ValuesEqual(a.State, b.State)
+}
+
+// ValuesEqual reports whether two values are equal with the semantics of
+// ContextsEqual.
+func ValuesEqual(a, b any) bool {
+	return valuesEqual(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func valuesEqual(a, b reflect.Value) bool {
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return isEmpty(a) && isEmpty(b)
+	}
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	switch {
+	case a.Kind() == reflect.Map && b.Kind() == reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			if !key.Type().AssignableTo(b.Type().Key()) {
+				return false
+			}
+			other := b.MapIndex(key)
+			if !other.IsValid() || !valuesEqual(iter.Value(), other) {
+				return false
+			}
+		}
+		return true
+	case isList(a) && isList(b):
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !valuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case a.Type() != b.Type():
+		return false
+	case a.CanInterface() && b.CanInterface():
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	return false
+}
+
+// indirect unwraps the interfaces holding a value.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
+
+// isEmpty reports whether v is nil or an empty map or slice.
+func isEmpty(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	case reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// isList reports whether v is a slice or an array.
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
+
+// number returns the value of a number as a float64.
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
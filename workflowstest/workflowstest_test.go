@@ -0,0 +1,67 @@
+package workflowstest
+
+import (
+	"encoding/json"
+	"testing"
+
+	workflowsgo "github.com/AstraBert/workflows-go"
+)
+
+func TestEventsEqual(t *testing.T) {
+	emitted := workflowsgo.NewBaseEvent("end", map[string]string{"output": "42"})
+	emitted.SourceStep = "answer"
+	fanOut := func(nextSteps ...string) *workflowsgo.BaseEvent {
+		event := workflowsgo.NewBaseEvent("", map[string]string{})
+		event.NextSteps = nextSteps
+		return event
+	}
+	var tests = []struct {
+		a, b *workflowsgo.BaseEvent
+		want bool
+	}{
+		{emitted, workflowsgo.NewBaseEvent("end", map[string]string{"output": "42"}), true},
+		{workflowsgo.NewBaseEvent("end", nil), workflowsgo.NewBaseEvent("end", map[string]string{}), true},
+		{emitted, workflowsgo.NewBaseEvent("retry", map[string]string{"output": "42"}), false},
+		{emitted, workflowsgo.NewBaseEvent("end", map[string]string{"output": "41"}), false},
+		{fanOut("summarize", "translate"), fanOut("summarize", "translate"), true},
+		{fanOut([]string{}...), workflowsgo.NewBaseEvent("", map[string]string{}), true},
+		{fanOut("summarize", "translate"), fanOut("summarize", "publish"), false},
+		{fanOut("summarize"), workflowsgo.NewBaseEvent("", map[string]string{}), false},
+		{nil, nil, true},
+		{emitted, nil, false},
+	}
+	for _, tt := range tests {
+		if got := EventsEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("Testing EventsEqual(%v, %v): want %v, got %v", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestContextsEqual(t *testing.T) {
+	store := map[string]any{"attempts": 3, "tags": []string{"go"}, "profile": map[string]any{"name": "Ada", "scores": []int{1, 2}}}
+	raw, err := json.Marshal(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		a, b *workflowsgo.BaseContext
+		want bool
+	}{
+		{workflowsgo.NewBaseContext(store, nil), workflowsgo.NewBaseContext(decoded, map[string]any{}), true},
+		{workflowsgo.NewBaseContext(map[string]any{"tags": []string{}}, nil), workflowsgo.NewBaseContext(map[string]any{"tags": nil}, nil), true},
+		{workflowsgo.NewBaseContext(store, nil), workflowsgo.NewBaseContext(map[string]any{"attempts": 4}, nil), false},
+		{workflowsgo.NewBaseContext(nil, map[string]any{"phase": "draft"}), workflowsgo.NewBaseContext(nil, map[string]any{"phase": "review"}), false},
+		{workflowsgo.NewBaseContext(map[string]any{"attempts": "3"}, nil), workflowsgo.NewBaseContext(map[string]any{"attempts": 3}, nil), false},
+		{nil, nil, true},
+		{workflowsgo.NewBaseContext(nil, nil), nil, false},
+	}
+	for i, tt := range tests {
+		if got := ContextsEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("Testing ContextsEqual, case %d: want %v, got %v", i, tt.want, got)
+		}
+	}
+}